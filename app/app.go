@@ -0,0 +1,84 @@
+package app
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/assets"
+	"github.com/mpanelo/gocookit/controllers"
+	"github.com/mpanelo/gocookit/metrics"
+	"github.com/mpanelo/gocookit/middleware"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+type Options struct {
+	CSRFKey []byte
+	Secure  bool
+	Logger  *slog.Logger
+
+	ReloadTemplates bool
+}
+
+func NewHandler(services *models.Services, opts Options) (http.Handler, error) {
+	vs := views.NewRegistry()
+	var assetsFS http.FileSystem = http.FS(assets.FS)
+	if opts.ReloadTemplates {
+		vs = views.NewReloadingRegistry("views")
+		assetsFS = http.Dir("./assets")
+	}
+
+	router := mux.NewRouter()
+
+	staticCT := controllers.NewStatic(vs)
+	usersCT := controllers.NewUsers(vs, services.User)
+	recipePolicy := policy.New(services.Workspace)
+
+	recipesCT := controllers.NewRecipes(vs, services.Recipe, services.Image, services.Collection, services.Workspace, services.Nutrition, services.CookLog, services.Comment, services.Favorite, services.Recommendation, recipePolicy, router)
+	collectionsCT := controllers.NewCollections(vs, services.Collection, services.Recipe, services.Image, recipePolicy, router)
+	workspacesCT := controllers.NewWorkspaces(vs, services.Workspace, services.Recipe, recipePolicy, router)
+	mealPlansCT := controllers.NewMealPlans(vs, services.MealPlan, services.Recipe, recipePolicy)
+	shoppingListsCT := controllers.NewShoppingLists(vs, services.ShoppingList, services.Recipe, recipePolicy, router)
+	pantryCT := controllers.NewPantry(vs, services.Pantry, services.Recipe, services.ShoppingList, recipePolicy, router)
+	nutritionCT := controllers.NewNutrition(vs, services.Nutrition, router)
+	favoritesCT := controllers.NewFavorites(vs, services.Favorite, recipePolicy, router)
+	healthCT := controllers.NewHealth(services, services.Image)
+
+	if err := vs.Check(); err != nil {
+		return nil, err
+	}
+
+	router.Use(middleware.TrackRoute, metrics.Middleware)
+
+	router.Handle("/", staticCT.Home)
+
+	assetsHandler := http.StripPrefix("/assets/", http.FileServer(assetsFS))
+	router.PathPrefix("/assets/").Handler(assetsHandler)
+
+	imagesHandler := http.StripPrefix("/images/", http.FileServer(http.Dir("./images/")))
+	router.PathPrefix("/images/").Handler(imagesHandler)
+
+	setUsersRoutes(router, usersCT)
+	setRecipesRoutes(router, recipesCT, pantryCT, favoritesCT, services.Recipe, recipePolicy)
+	setCollectionsRoutes(router, collectionsCT)
+	setWorkspacesRoutes(router, workspacesCT)
+	setMealPlansRoutes(router, mealPlansCT)
+	setShoppingListsRoutes(router, shoppingListsCT)
+	setPantryRoutes(router, pantryCT)
+	setNutritionRoutes(router, nutritionCT, services.Recipe, recipePolicy)
+
+	csrfMw := csrf.Protect(opts.CSRFKey, csrf.Secure(opts.Secure))
+
+	userMw := middleware.User{UserService: services.User}
+
+	opsRouter := mux.NewRouter()
+	setOpsRoutes(opsRouter, healthCT)
+	opsRouter.NotFoundHandler = csrfMw(userMw.Apply(router))
+
+	requestLogger := middleware.RequestLogger{Logger: opts.Logger}
+
+	return requestLogger.Apply(opsRouter), nil
+}
@@ -0,0 +1,143 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+func TestCollectionRoutes(t *testing.T) {
+	owner := []role{roleOwner}
+	ctx := context.Background()
+
+	collectionRecipes := func(c routeCase) []models.Recipe {
+		recipes, _ := c.h.Services.Collection.Recipes(ctx, c.f.collection.ID)
+		return recipes
+	}
+
+	runRouteTests(t, []routeTest{
+		{
+			name: "index", method: http.MethodGet, path: "/collections",
+			allowed: owner, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/collections/{collection}"`))
+			},
+		},
+		{
+			name: "create", method: http.MethodPost, path: "/collections",
+			form:    url.Values{"title": {"Holidays"}},
+			allowed: roles,
+			effect: func(c routeCase) bool {
+				collections, _ := c.h.Services.Collection.ByUserID(ctx, c.user.ID)
+				for _, collection := range collections {
+					if collection.Title == "Holidays" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "new", method: http.MethodGet, path: "/collections/new",
+			allowed: roles,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, `action="/collections"`)
+			},
+		},
+		{
+			name: "show", method: http.MethodGet, path: "/collections/{collection}",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/recipes/{recipe}"`))
+			},
+		},
+		{
+			name: "edit", method: http.MethodGet, path: "/collections/{collection}/edit",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`action="/collections/{collection}"`))
+			},
+		},
+		{
+			name: "print", method: http.MethodGet, path: "/collections/{collection}/print",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, "2 cups flour")
+			},
+		},
+		{
+			name: "update", method: http.MethodPost, path: "/collections/{collection}",
+			form:    url.Values{"title": {"Weekends"}},
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				collection, err := c.h.Services.Collection.ByID(ctx, c.f.collection.ID)
+				return err == nil && collection.Title == "Weekends"
+			},
+		},
+		{
+			name: "delete", method: http.MethodPost, path: "/collections/{collection}/delete",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.Collection.ByID(ctx, c.f.collection.ID)
+				return err == models.ErrNotFound
+			},
+		},
+		{
+			name: "add recipe", method: http.MethodPost, path: "/collections/{collection}/recipes",
+			form:    url.Values{"recipe_id": {"{personal}"}},
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				recipes := collectionRecipes(c)
+				return len(recipes) == 3 && recipes[2].ID == c.f.personal.ID
+			},
+		},
+		{
+			name: "move recipe", method: http.MethodPost, path: "/collections/{collection}/recipes/{duplicate}/move",
+			form:    url.Values{"position": {"0"}},
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				recipes := collectionRecipes(c)
+				return len(recipes) == 2 && recipes[0].ID == c.f.duplicate.ID
+			},
+		},
+		{
+			name: "remove recipe", method: http.MethodPost, path: "/collections/{collection}/recipes/{recipe}/delete",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				recipes := collectionRecipes(c)
+				return len(recipes) == 1 && recipes[0].ID == c.f.duplicate.ID
+			},
+		},
+	})
+}
+
+func TestCollectionRejectsUnviewableRecipe(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	ctx := context.Background()
+
+	collection := models.Collection{UserID: users[roleStranger].ID, Title: "Borrowed"}
+	if err := h.Services.Collection.Create(ctx, &collection); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+	h.UseSession(string(roleStranger))
+
+	path := fmt.Sprintf("/collections/%d/recipes", collection.ID)
+	res := h.PostForm("/collections/new", path, url.Values{"recipe_id": {fmt.Sprint(f.recipe.ID)}})
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("POST %s = %d, want %d", path, res.StatusCode, http.StatusNotFound)
+	}
+
+	recipes, err := h.Services.Collection.Recipes(ctx, collection.ID)
+	if err != nil {
+		t.Fatalf("Recipes: %v", err)
+	}
+	if len(recipes) != 0 {
+		t.Errorf("collection holds %d recipes the stranger cannot see", len(recipes))
+	}
+}
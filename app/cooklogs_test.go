@@ -0,0 +1,71 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/mpanelo/gocookit/harness"
+	"github.com/mpanelo/gocookit/models"
+)
+
+func TestCookLogPhotosAreStoredPerCookLog(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	ctx := context.Background()
+	h.UseSession(string(roleOwner))
+
+	form := url.Values{"cooked_on": {"2024-03-04"}, "rating": {"4"}}
+	photo := harness.File{Name: "dinner.jpg", Content: []byte("dinner")}
+	for i := 0; i < 2; i++ {
+		res := h.PostMultipart(f.path("/recipes/{recipe}"), f.path("/recipes/{recipe}/cooklogs"), form, "photo", photo)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("log cook = %d\n%s", res.StatusCode, res.Body)
+		}
+	}
+
+	cookLogs, err := h.Services.CookLog.RecentByRecipeID(ctx, f.recipe.ID)
+	if err != nil {
+		t.Fatalf("RecentByRecipeID: %v", err)
+	}
+	var logged []models.CookLog
+	for _, cookLog := range cookLogs {
+		if cookLog.UserID == users[roleOwner].ID {
+			logged = append(logged, cookLog)
+		}
+	}
+	if len(logged) != 2 {
+		t.Fatalf("logged %d cook logs, want 2", len(logged))
+	}
+	for _, cookLog := range logged {
+		photos, err := h.Services.Image.PhotosByCookLogID(ctx, cookLog.ID)
+		if err != nil {
+			t.Fatalf("PhotosByCookLogID: %v", err)
+		}
+		if want := []models.Photo{{CookLogID: cookLog.ID, Filename: "dinner.jpg"}}; !reflect.DeepEqual(photos, want) {
+			t.Errorf("PhotosByCookLogID(%d) = %v, want %v", cookLog.ID, photos, want)
+		}
+	}
+
+	images, err := h.Services.Image.ByRecipeID(ctx, f.recipe.ID)
+	if err != nil {
+		t.Fatalf("ByRecipeID: %v", err)
+	}
+	if want := []models.Image{{RecipeID: f.recipe.ID, Filename: "bread.jpg"}}; !reflect.DeepEqual(images, want) {
+		t.Errorf("recipe gallery = %v, want %v", images, want)
+	}
+
+	h.PostForm(f.path("/recipes/{recipe}"), fmt.Sprintf("/recipes/%d/cooklogs/%d/delete", f.recipe.ID, logged[0].ID), nil)
+	for i, want := range []int{0, 1} {
+		photos, err := h.Services.Image.PhotosByCookLogID(ctx, logged[i].ID)
+		if err != nil {
+			t.Fatalf("PhotosByCookLogID: %v", err)
+		}
+		if len(photos) != want {
+			t.Errorf("cook log %d has %d photos after deleting cook log %d, want %d", logged[i].ID, len(photos), logged[0].ID, want)
+		}
+	}
+}
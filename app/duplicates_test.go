@@ -0,0 +1,51 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+func TestMergeKeepsCollidingImages(t *testing.T) {
+	h, users := newHarness(t)
+	canonical := newFixture(t, h, users)
+	duplicate := newFixture(t, h, users)
+	ctx := context.Background()
+	h.UseSession(string(roleOwner))
+
+	res := h.PostForm("/recipes/duplicates", "/recipes/merge", url.Values{
+		"canonical_id": {fmt.Sprint(canonical.recipe.ID)},
+		"recipe_ids":   {fmt.Sprint(canonical.recipe.ID), fmt.Sprint(duplicate.recipe.ID)},
+	})
+	if res.StatusCode != http.StatusOK || res.URL.Path != canonical.path("/recipes/{recipe}") {
+		t.Fatalf("merge = %d %s, want the canonical recipe\n%s", res.StatusCode, res.URL.Path, res.Body)
+	}
+
+	images, err := h.Services.Image.ByRecipeID(ctx, canonical.recipe.ID)
+	if err != nil {
+		t.Fatalf("ByRecipeID: %v", err)
+	}
+	want := []models.Image{
+		{RecipeID: canonical.recipe.ID, Filename: "bread-2.jpg"},
+		{RecipeID: canonical.recipe.ID, Filename: "bread.jpg"},
+	}
+	if !reflect.DeepEqual(images, want) {
+		t.Errorf("canonical images = %v, want %v", images, want)
+	}
+
+	if images, err := h.Services.Image.ByRecipeID(ctx, duplicate.recipe.ID); err != nil || len(images) != 0 {
+		t.Errorf("duplicate images = %v, %v, want none", images, err)
+	}
+	if _, err := h.Services.Recipe.ByID(ctx, duplicate.recipe.ID); err != models.ErrNotFound {
+		t.Errorf("duplicate after merge: err = %v, want %v", err, models.ErrNotFound)
+	}
+	if !strings.Contains(res.Body, "bread-2.jpg") {
+		t.Errorf("canonical recipe page does not show the merged image\n%s", res.Body)
+	}
+}
@@ -0,0 +1,41 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFavoritesListOnlyViewableRecipes(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	ctx := context.Background()
+	link := f.path(`href="/recipes/{recipe}"`)
+
+	for _, r := range []role{roleOwner, roleViewer} {
+		h.UseSession(string(r))
+		h.PostForm("/recipes/new", f.path("/recipes/{recipe}/favorite"), nil)
+		if res := h.Get("/favorites"); !strings.Contains(res.Body, link) {
+			t.Fatalf("favorites for %s do not list the favorited recipe\n%s", r, res.Body)
+		}
+	}
+
+	h.UseSession(string(roleStranger))
+	if res := h.Get("/favorites"); strings.Contains(res.Body, link) {
+		t.Errorf("favorites for %s list a recipe they never favorited", roleStranger)
+	}
+
+	if err := h.Services.Workspace.RemoveMembership(ctx, f.workspace.ID, users[roleViewer].ID); err != nil {
+		t.Fatalf("remove viewer: %v", err)
+	}
+	h.UseSession(string(roleViewer))
+	if res := h.Get("/favorites"); strings.Contains(res.Body, link) {
+		t.Errorf("favorites for %s list a recipe they can no longer see", roleViewer)
+	}
+
+	h.UseSession(string(roleOwner))
+	h.PostForm("/recipes/new", f.path("/recipes/{recipe}/favorite"), nil)
+	if res := h.Get("/favorites"); strings.Contains(res.Body, link) {
+		t.Errorf("favorites for %s still list the recipe after toggling it off", roleOwner)
+	}
+}
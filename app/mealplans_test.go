@@ -0,0 +1,83 @@
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+func TestMealPlanRoutes(t *testing.T) {
+	owner := []role{roleOwner}
+	everyMember := []role{roleOwner, roleWorkspaceOwner, roleEditor, roleViewer}
+	ctx := context.Background()
+
+	week := func(c routeCase, offset int) []models.MealPlanEntry {
+		from := models.StartOfWeek(c.f.entry.Date).AddDate(0, 0, offset)
+		entries, _ := c.h.Services.MealPlan.ByUserIDBetween(ctx, c.user.ID, from, from.AddDate(0, 0, 7))
+		return entries
+	}
+
+	runRouteTests(t, []routeTest{
+		{
+			name: "week", method: http.MethodGet, path: "/mealplan?week={date}",
+			allowed: owner, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`action="/mealplan/entries/{entry}/delete"`))
+			},
+		},
+		{
+			name: "month", method: http.MethodGet, path: "/mealplan/month",
+			allowed: owner, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/recipes/{recipe}"`))
+			},
+		},
+		{
+			name: "repeat", method: http.MethodPost, path: "/mealplan/repeat",
+			form:    url.Values{"week": {"{date}"}},
+			allowed: owner, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				for _, entry := range week(c, 7) {
+					if entry.RecipeID == c.f.recipe.ID {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "create", method: http.MethodPost, path: "/mealplan/entries",
+			form:    url.Values{"recipe_id": {"{recipe}"}, "date": {"{date}"}, "slot": {string(models.MealSlotLunch)}},
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				for _, entry := range week(c, 0) {
+					if entry.RecipeID == c.f.recipe.ID && entry.Slot == models.MealSlotLunch {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "update", method: http.MethodPost, path: "/mealplan/entries/{entry}",
+			form:    url.Values{"slot": {string(models.MealSlotBreakfast)}},
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				entry, err := c.h.Services.MealPlan.ByID(ctx, c.f.entry.ID)
+				return err == nil && entry.Slot == models.MealSlotBreakfast
+			},
+		},
+		{
+			name: "delete", method: http.MethodPost, path: "/mealplan/entries/{entry}/delete",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.MealPlan.ByID(ctx, c.f.entry.ID)
+				return err == models.ErrNotFound
+			},
+		},
+	})
+}
@@ -0,0 +1,66 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ingredientKey = regexp.MustCompile(`name="matches\.0\.ingredient" value="([^"]+)"`)
+
+func TestNutritionOverridesMatch(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	ctx := context.Background()
+	h.UseSession(string(roleOwner))
+
+	editPath := f.path("/recipes/{recipe}/nutrition")
+	res := h.Get(editPath)
+	if !strings.Contains(res.Body, "Automatic (all-purpose flour)") {
+		t.Fatalf("GET %s does not match flour automatically\n%s", editPath, res.Body)
+	}
+	m := ingredientKey.FindStringSubmatch(res.Body)
+	if m == nil {
+		t.Fatalf("GET %s has no ingredient to match\n%s", editPath, res.Body)
+	}
+	automatic, err := h.Services.Nutrition.Label(ctx, &f.recipe)
+	if err != nil {
+		t.Fatalf("Label: %v", err)
+	}
+
+	res = h.PostForm(editPath, editPath, url.Values{"matches.0.ingredient": {m[1]}, "matches.0.food_id": {"whole_wheat_flour"}})
+	if want := f.path("/recipes/{recipe}"); res.StatusCode != http.StatusOK || res.URL.Path != want {
+		t.Fatalf("POST %s = %d %s, want %d %s", editPath, res.StatusCode, res.URL.Path, http.StatusOK, want)
+	}
+
+	matches, err := h.Services.Nutrition.ByRecipeID(ctx, f.recipe.ID)
+	if err != nil {
+		t.Fatalf("ByRecipeID: %v", err)
+	}
+	if len(matches) != 1 || matches[0].FoodID != "whole_wheat_flour" {
+		t.Fatalf("matches = %+v, want flour matched to whole_wheat_flour", matches)
+	}
+	label, err := h.Services.Nutrition.Label(ctx, &f.recipe)
+	if err != nil {
+		t.Fatalf("Label: %v", err)
+	}
+	if label.Total.Calories == automatic.Total.Calories {
+		t.Errorf("calories = %.0f after overriding the match, want a change from %.0f", label.Total.Calories, automatic.Total.Calories)
+	}
+	if calories := fmt.Sprintf("%.0f", label.PerServing.Calories); !strings.Contains(res.Body, "<span>"+calories+"</span>") {
+		t.Errorf("recipe page does not show %s calories\n%s", calories, res.Body)
+	}
+
+	h.PostForm(editPath, editPath, url.Values{"matches.0.ingredient": {m[1]}, "matches.0.food_id": {""}})
+	matches, err = h.Services.Nutrition.ByRecipeID(ctx, f.recipe.ID)
+	if err != nil {
+		t.Fatalf("ByRecipeID: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("matches after resetting to automatic = %+v, want none", matches)
+	}
+}
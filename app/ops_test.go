@@ -0,0 +1,45 @@
+package app_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/harness"
+)
+
+func TestOpsRoutes(t *testing.T) {
+	h := harness.New(t, "")
+	h.Get("/signin")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/healthz", "ok"},
+		{"/readyz", "ok"},
+		{"/metrics", `gocookit_http_requests_total{`},
+	}
+
+	for _, tt := range tests {
+		res := h.Get(tt.path)
+		if res.StatusCode != http.StatusOK || !strings.Contains(res.Body, tt.want) {
+			t.Errorf("GET %s = %d, want %d containing %q\n%s", tt.path, res.StatusCode, http.StatusOK, tt.want, res.Body)
+		}
+	}
+}
+
+func TestReadyzReportsDatabaseOutage(t *testing.T) {
+	h := harness.New(t, "")
+	if err := h.Services.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	res := h.Get("/readyz")
+	if res.StatusCode != http.StatusServiceUnavailable || !strings.Contains(res.Body, "database unavailable") {
+		t.Errorf("GET /readyz = %d %q, want %d database unavailable", res.StatusCode, res.Body, http.StatusServiceUnavailable)
+	}
+	if res := h.Get("/healthz"); res.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz = %d, want %d while the database is down", res.StatusCode, http.StatusOK)
+	}
+}
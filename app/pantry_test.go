@@ -0,0 +1,57 @@
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+func TestPantryRoutes(t *testing.T) {
+	owner := []role{roleOwner}
+	ctx := context.Background()
+
+	runRouteTests(t, []routeTest{
+		{
+			name: "index", method: http.MethodGet, path: "/pantry",
+			allowed: owner, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`action="/pantry/{pantry}"`))
+			},
+		},
+		{
+			name: "create", method: http.MethodPost, path: "/pantry",
+			form:    url.Values{"name": {"yeast"}, "quantity": {"7"}, "unit": {"g"}},
+			allowed: roles,
+			effect: func(c routeCase) bool {
+				items, _ := c.h.Services.Pantry.ByUserID(ctx, c.user.ID)
+				for _, item := range items {
+					if item.Name == "yeast" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "update", method: http.MethodPost, path: "/pantry/{pantry}",
+			form:    url.Values{"name": {"milk"}, "quantity": {"3"}, "unit": {"cup"}},
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				item, err := c.h.Services.Pantry.ByID(ctx, c.f.pantryItem.ID)
+				return err == nil && item.Quantity == 3
+			},
+		},
+		{
+			name: "delete", method: http.MethodPost, path: "/pantry/{pantry}/delete",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.Pantry.ByID(ctx, c.f.pantryItem.ID)
+				return err == models.ErrNotFound
+			},
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRefreshingDoesNotInflateCoViews(t *testing.T) {
+	h, users := newHarness(t)
+	a := newFixture(t, h, users)
+	b := newFixture(t, h, users)
+	h.UseSession(string(roleOwner))
+
+	h.Get(a.path("/recipes/{recipe}"))
+	for i := 0; i < 5; i++ {
+		h.Get(b.path("/recipes/{recipe}"))
+	}
+	h.Get(a.path("/recipes/{recipe}"))
+
+	coViews, err := h.Services.Recommendation.CoViews(context.Background())
+	if err != nil {
+		t.Fatalf("CoViews: %v", err)
+	}
+	if got := coViews[[2]uint{a.recipe.ID, b.recipe.ID}]; got != 1 {
+		t.Errorf("co-views after refreshing = %d, want 1", got)
+	}
+}
@@ -0,0 +1,254 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/controllers"
+	"github.com/mpanelo/gocookit/metrics"
+	"github.com/mpanelo/gocookit/middleware"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+)
+
+func setOpsRoutes(router *mux.Router, healthCT *controllers.Health) {
+	router.HandleFunc("/healthz", healthCT.Healthz).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", healthCT.Readyz).Methods(http.MethodGet)
+	router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+}
+
+func setUsersRoutes(router *mux.Router, usersCT *controllers.Users) {
+	router.Handle("/signup", usersCT.SignUpView).Methods(http.MethodGet)
+	router.Handle("/signin", usersCT.SignInView).Methods(http.MethodGet)
+	router.HandleFunc("/users", usersCT.SignUp).Methods(http.MethodPost)
+	router.HandleFunc("/signin", usersCT.SignIn).Methods(http.MethodPost)
+}
+
+func setRecipesRoutes(router *mux.Router, recipesCT *controllers.Recipes, pantryCT *controllers.Pantry, favoritesCT *controllers.Favorites, rs models.RecipeService, p *policy.Policy) {
+	requireUserMw := middleware.RequireUser{}
+	viewRecipeMw := middleware.Recipe{RecipeService: rs, Policy: p, Action: policy.ActionView}
+	editRecipeMw := middleware.Recipe{RecipeService: rs, Policy: p, Action: policy.ActionEdit}
+	manageRecipeMw := middleware.Recipe{RecipeService: rs, Policy: p, Action: policy.ActionManage}
+
+	router.
+		Handle("/recipes", requireUserMw.ApplyFn(recipesCT.Index)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/recipes", requireUserMw.ApplyFn(recipesCT.Create)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/new", requireUserMw.Apply(recipesCT.NewView)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/recipes/duplicates", requireUserMw.ApplyFn(recipesCT.Duplicates)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/recipes/merge", requireUserMw.ApplyFn(recipesCT.Merge)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/preview", requireUserMw.ApplyFn(recipesCT.Preview)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/cookable", requireUserMw.ApplyFn(pantryCT.Cookable)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/recipes/{id:[0-9]+}", requireUserMw.Apply(viewRecipeMw.ApplyFn(recipesCT.Show))).
+		Methods(http.MethodGet).
+		Name(controllers.RouteRecipeShow)
+	router.
+		Handle("/recipes/{id:[0-9]+}/edit", requireUserMw.Apply(editRecipeMw.ApplyFn(recipesCT.Edit))).
+		Methods(http.MethodGet).
+		Name(controllers.RouteRecipeEdit)
+	router.
+		Handle("/recipes/{id:[0-9]+}", requireUserMw.Apply(editRecipeMw.ApplyFn(recipesCT.Update))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/fork", requireUserMw.Apply(viewRecipeMw.ApplyFn(recipesCT.Fork))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/workspace", requireUserMw.Apply(manageRecipeMw.ApplyFn(recipesCT.Share))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/missing", requireUserMw.Apply(viewRecipeMw.ApplyFn(pantryCT.AddMissing))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/favorite", requireUserMw.Apply(viewRecipeMw.ApplyFn(favoritesCT.Toggle))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/favorites", requireUserMw.ApplyFn(favoritesCT.Index)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/recipes/{id:[0-9]+}/cooklogs", requireUserMw.Apply(viewRecipeMw.ApplyFn(recipesCT.CookLogCreate))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/cooklogs/{cookLogID:[0-9]+}/delete", requireUserMw.Apply(viewRecipeMw.ApplyFn(recipesCT.CookLogDelete))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/comments", requireUserMw.Apply(viewRecipeMw.ApplyFn(recipesCT.CommentCreate))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/comments/{commentID:[0-9]+}", requireUserMw.Apply(viewRecipeMw.ApplyFn(recipesCT.CommentUpdate))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/comments/{commentID:[0-9]+}/hide", requireUserMw.Apply(manageRecipeMw.ApplyFn(recipesCT.CommentHide))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/comments/{commentID:[0-9]+}/delete", requireUserMw.Apply(viewRecipeMw.ApplyFn(recipesCT.CommentDelete))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/images", requireUserMw.Apply(editRecipeMw.ApplyFn(recipesCT.ImageUpload))).
+		Methods(http.MethodPost)
+	router.
+		Handle("/recipes/{id:[0-9]+}/images/{filename}/delete", requireUserMw.Apply(editRecipeMw.ApplyFn(recipesCT.ImageDelete))).
+		Methods(http.MethodPost)
+}
+
+func setCollectionsRoutes(router *mux.Router, collectionsCT *controllers.Collections) {
+	requireUserMw := middleware.RequireUser{}
+	router.
+		Handle("/collections", requireUserMw.ApplyFn(collectionsCT.Index)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/collections", requireUserMw.ApplyFn(collectionsCT.Create)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/collections/new", requireUserMw.Apply(collectionsCT.NewView)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/collections/{id:[0-9]+}", requireUserMw.ApplyFn(collectionsCT.Show)).
+		Methods(http.MethodGet).
+		Name(controllers.RouteCollectionShow)
+	router.
+		Handle("/collections/{id:[0-9]+}/edit", requireUserMw.ApplyFn(collectionsCT.Edit)).
+		Methods(http.MethodGet).
+		Name(controllers.RouteCollectionEdit)
+	router.
+		Handle("/collections/{id:[0-9]+}/print", requireUserMw.ApplyFn(collectionsCT.Print)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/collections/{id:[0-9]+}", requireUserMw.ApplyFn(collectionsCT.Update)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/collections/{id:[0-9]+}/delete", requireUserMw.ApplyFn(collectionsCT.Delete)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/collections/{id:[0-9]+}/recipes", requireUserMw.ApplyFn(collectionsCT.AddRecipe)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/collections/{id:[0-9]+}/recipes/{recipeID:[0-9]+}/move", requireUserMw.ApplyFn(collectionsCT.MoveRecipe)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/collections/{id:[0-9]+}/recipes/{recipeID:[0-9]+}/delete", requireUserMw.ApplyFn(collectionsCT.RemoveRecipe)).
+		Methods(http.MethodPost)
+}
+
+func setWorkspacesRoutes(router *mux.Router, workspacesCT *controllers.Workspaces) {
+	requireUserMw := middleware.RequireUser{}
+	router.
+		Handle("/workspaces", requireUserMw.ApplyFn(workspacesCT.Index)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/workspaces", requireUserMw.ApplyFn(workspacesCT.Create)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/workspaces/new", requireUserMw.Apply(workspacesCT.NewView)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/workspaces/{id:[0-9]+}", requireUserMw.ApplyFn(workspacesCT.Show)).
+		Methods(http.MethodGet).
+		Name(controllers.RouteWorkspaceShow)
+	router.
+		Handle("/workspaces/{id:[0-9]+}", requireUserMw.ApplyFn(workspacesCT.Update)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/workspaces/{id:[0-9]+}/invitations", requireUserMw.ApplyFn(workspacesCT.Invite)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/workspaces/{id:[0-9]+}/members/{userID:[0-9]+}", requireUserMw.ApplyFn(workspacesCT.UpdateMember)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/workspaces/{id:[0-9]+}/members/{userID:[0-9]+}/delete", requireUserMw.ApplyFn(workspacesCT.RemoveMember)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/invitations/{token}", requireUserMw.ApplyFn(workspacesCT.Invitation)).
+		Methods(http.MethodGet).
+		Name(controllers.RouteInvitationShow)
+	router.
+		Handle("/invitations/{token}", requireUserMw.ApplyFn(workspacesCT.AcceptInvitation)).
+		Methods(http.MethodPost)
+}
+
+func setMealPlansRoutes(router *mux.Router, mealPlansCT *controllers.MealPlans) {
+	requireUserMw := middleware.RequireUser{}
+	router.
+		Handle("/mealplan", requireUserMw.ApplyFn(mealPlansCT.Week)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/mealplan/month", requireUserMw.ApplyFn(mealPlansCT.Month)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/mealplan/repeat", requireUserMw.ApplyFn(mealPlansCT.Repeat)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/mealplan/entries", requireUserMw.ApplyFn(mealPlansCT.Create)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/mealplan/entries/{id:[0-9]+}", requireUserMw.ApplyFn(mealPlansCT.Update)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/mealplan/entries/{id:[0-9]+}/delete", requireUserMw.ApplyFn(mealPlansCT.Delete)).
+		Methods(http.MethodPost)
+}
+
+func setShoppingListsRoutes(router *mux.Router, shoppingListsCT *controllers.ShoppingLists) {
+	requireUserMw := middleware.RequireUser{}
+	router.
+		Handle("/shoppinglists", requireUserMw.ApplyFn(shoppingListsCT.Index)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/shoppinglists", requireUserMw.ApplyFn(shoppingListsCT.Create)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/shoppinglists/new", requireUserMw.ApplyFn(shoppingListsCT.New)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/shoppinglists/{id:[0-9]+}", requireUserMw.ApplyFn(shoppingListsCT.Show)).
+		Methods(http.MethodGet).
+		Name(controllers.RouteShoppingListShow)
+	router.
+		Handle("/shoppinglists/{id:[0-9]+}/export", requireUserMw.ApplyFn(shoppingListsCT.Export)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/shoppinglists/{id:[0-9]+}/delete", requireUserMw.ApplyFn(shoppingListsCT.Delete)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/shoppinglists/{id:[0-9]+}/items/{itemID:[0-9]+}/toggle", requireUserMw.ApplyFn(shoppingListsCT.ToggleItem)).
+		Methods(http.MethodPost)
+}
+
+func setPantryRoutes(router *mux.Router, pantryCT *controllers.Pantry) {
+	requireUserMw := middleware.RequireUser{}
+	router.
+		Handle("/pantry", requireUserMw.ApplyFn(pantryCT.Index)).
+		Methods(http.MethodGet)
+	router.
+		Handle("/pantry", requireUserMw.ApplyFn(pantryCT.Create)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/pantry/{id:[0-9]+}", requireUserMw.ApplyFn(pantryCT.Update)).
+		Methods(http.MethodPost)
+	router.
+		Handle("/pantry/{id:[0-9]+}/delete", requireUserMw.ApplyFn(pantryCT.Delete)).
+		Methods(http.MethodPost)
+}
+
+func setNutritionRoutes(router *mux.Router, nutritionCT *controllers.Nutrition, rs models.RecipeService, p *policy.Policy) {
+	requireUserMw := middleware.RequireUser{}
+	editRecipeMw := middleware.Recipe{RecipeService: rs, Policy: p, Action: policy.ActionEdit}
+	router.
+		Handle("/recipes/{id:[0-9]+}/nutrition", requireUserMw.Apply(editRecipeMw.ApplyFn(nutritionCT.Edit))).
+		Methods(http.MethodGet)
+	router.
+		Handle("/recipes/{id:[0-9]+}/nutrition", requireUserMw.Apply(editRecipeMw.ApplyFn(nutritionCT.Update))).
+		Methods(http.MethodPost)
+}
@@ -0,0 +1,591 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mpanelo/gocookit/harness"
+	"github.com/mpanelo/gocookit/models"
+)
+
+const password = "password1"
+
+type role string
+
+const (
+	roleOwner          role = "owner"
+	roleWorkspaceOwner role = "workspace owner"
+	roleEditor         role = "workspace editor"
+	roleViewer         role = "workspace viewer"
+	roleStranger       role = "stranger"
+)
+
+var roles = []role{roleOwner, roleWorkspaceOwner, roleEditor, roleViewer, roleStranger}
+
+var emails = map[role]string{
+	roleOwner:          "owner@example.com",
+	roleWorkspaceOwner: "coowner@example.com",
+	roleEditor:         "editor@example.com",
+	roleViewer:         "viewer@example.com",
+	roleStranger:       "stranger@example.com",
+}
+
+type fixture struct {
+	recipe     models.Recipe
+	duplicate  models.Recipe
+	comment    models.Comment
+	cookLog    models.CookLog
+	personal   models.Recipe
+	workspace  models.Workspace
+	bakery     models.Workspace
+	invitation *models.Invitation
+	collection models.Collection
+	entry      models.MealPlanEntry
+	list       models.ShoppingList
+	pantryItem models.PantryItem
+	viewerID   uint
+}
+
+func (f *fixture) path(pattern string) string {
+	return strings.NewReplacer(
+		"{recipe}", fmt.Sprint(f.recipe.ID),
+		"{duplicate}", fmt.Sprint(f.duplicate.ID),
+		"{comment}", fmt.Sprint(f.comment.ID),
+		"{cooklog}", fmt.Sprint(f.cookLog.ID),
+		"{workspace}", fmt.Sprint(f.workspace.ID),
+		"{bakery}", fmt.Sprint(f.bakery.ID),
+		"{personal}", fmt.Sprint(f.personal.ID),
+		"{token}", f.invitation.Token,
+		"{collection}", fmt.Sprint(f.collection.ID),
+		"{entry}", fmt.Sprint(f.entry.ID),
+		"{date}", f.entry.Date.Format(models.DateLayout),
+		"{list}", fmt.Sprint(f.list.ID),
+		"{item}", fmt.Sprint(f.list.Items[0].ID),
+		"{pantry}", fmt.Sprint(f.pantryItem.ID),
+		"{viewer}", fmt.Sprint(f.viewerID),
+	).Replace(pattern)
+}
+
+type routeTest struct {
+	name    string
+	method  string
+	path    string
+	form    url.Values
+	field   string
+	files   []harness.File
+	allowed []role
+	denied  int
+	effect  func(c routeCase) bool
+}
+
+type routeCase struct {
+	h    *harness.Harness
+	f    *fixture
+	user *models.User
+	res  *harness.Response
+}
+
+func TestRecipeRouteAuthorization(t *testing.T) {
+	everyone := roles
+	everyMember := []role{roleOwner, roleWorkspaceOwner, roleEditor, roleViewer}
+	editors := []role{roleOwner, roleWorkspaceOwner, roleEditor}
+	managers := []role{roleOwner, roleWorkspaceOwner}
+	ctx := context.Background()
+
+	tests := []routeTest{
+		{
+			name: "index", method: http.MethodGet, path: "/recipes",
+			allowed: []role{roleOwner}, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/recipes/{recipe}"`))
+			},
+		},
+		{
+			name: "create", method: http.MethodPost, path: "/recipes",
+			form:    url.Values{"title": {"Scone"}, "ingredients": {"1 cup flour"}},
+			allowed: everyone,
+			effect: func(c routeCase) bool {
+				recipes, _ := c.h.Services.Recipe.ByUserID(ctx, c.user.ID)
+				for _, recipe := range recipes {
+					if recipe.Title == "Scone" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "new", method: http.MethodGet, path: "/recipes/new",
+			allowed: everyone,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, `action="/recipes"`)
+			},
+		},
+		{
+			name: "duplicates", method: http.MethodGet, path: "/recipes/duplicates",
+			allowed: []role{roleOwner}, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/recipes/{duplicate}"`))
+			},
+		},
+		{
+			name: "merge", method: http.MethodPost, path: "/recipes/merge",
+			form:    url.Values{"canonical_id": {"{recipe}"}, "recipe_ids": {"{recipe}", "{duplicate}"}},
+			allowed: managers, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.Recipe.ByID(ctx, c.f.duplicate.ID)
+				return err == models.ErrNotFound
+			},
+		},
+		{
+			name: "preview", method: http.MethodPost, path: "/recipes/preview",
+			form:    url.Values{"source": {"**crusty**"}},
+			allowed: everyone,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, "<strong>crusty</strong>")
+			},
+		},
+		{
+			name: "cookable", method: http.MethodGet, path: "/recipes/cookable",
+			allowed: []role{roleOwner}, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/recipes/{recipe}"`))
+			},
+		},
+		{
+			name: "show", method: http.MethodGet, path: "/recipes/{recipe}",
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, "2 cups flour")
+			},
+		},
+		{
+			name: "edit", method: http.MethodGet, path: "/recipes/{recipe}/edit",
+			allowed: editors, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`action="/recipes/{recipe}"`))
+			},
+		},
+		{
+			name: "update", method: http.MethodPost, path: "/recipes/{recipe}",
+			form:    url.Values{"title": {"Rye"}, "ingredients": {"2 cups flour"}},
+			allowed: editors, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				recipe, err := c.h.Services.Recipe.ByID(ctx, c.f.recipe.ID)
+				return err == nil && recipe.Title == "Rye"
+			},
+		},
+		{
+			name: "fork", method: http.MethodPost, path: "/recipes/{recipe}/fork",
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				forks, _ := c.h.Services.Recipe.ByForkedFromID(ctx, c.f.recipe.ID)
+				for _, fork := range forks {
+					if fork.UserID == c.user.ID {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "share", method: http.MethodPost, path: "/recipes/{recipe}/workspace",
+			form:    url.Values{"workspace_id": {"{bakery}"}},
+			allowed: managers, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				recipe, err := c.h.Services.Recipe.ByID(ctx, c.f.recipe.ID)
+				return err == nil && recipe.WorkspaceID != nil && *recipe.WorkspaceID == c.f.bakery.ID
+			},
+		},
+		{
+			name: "add missing", method: http.MethodPost, path: "/recipes/{recipe}/missing",
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				lists, _ := c.h.Services.ShoppingList.ByUserID(ctx, c.user.ID)
+				for _, list := range lists {
+					if list.Title == "Missing for Bread" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "favorite", method: http.MethodPost, path: "/recipes/{recipe}/favorite",
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				favorite, _ := c.h.Services.Favorite.IsFavorite(ctx, c.user.ID, c.f.recipe.ID)
+				return favorite
+			},
+		},
+		{
+			name: "log cook", method: http.MethodPost, path: "/recipes/{recipe}/cooklogs",
+			form:    url.Values{"cooked_on": {"2024-03-04"}, "rating": {"4"}},
+			field:   "photo",
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				cookLogs, _ := c.h.Services.CookLog.RecentByRecipeID(ctx, c.f.recipe.ID)
+				for _, cookLog := range cookLogs {
+					if cookLog.UserID == c.user.ID && cookLog.Rating == 4 {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "delete cook log", method: http.MethodPost, path: "/recipes/{recipe}/cooklogs/{cooklog}/delete",
+			allowed: []role{roleOwner, roleWorkspaceOwner, roleViewer}, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.CookLog.ByID(ctx, c.f.cookLog.ID)
+				return err == models.ErrNotFound
+			},
+		},
+		{
+			name: "comment", method: http.MethodPost, path: "/recipes/{recipe}/comments",
+			form:    url.Values{"body": {"Lovely"}},
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				comments, _ := c.h.Services.Comment.ByRecipeID(ctx, c.f.recipe.ID)
+				for _, comment := range comments {
+					if comment.UserID == c.user.ID && comment.Body == "Lovely" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "edit comment", method: http.MethodPost, path: "/recipes/{recipe}/comments/{comment}",
+			form:    url.Values{"body": {"Edited"}},
+			allowed: []role{roleViewer}, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				comment, err := c.h.Services.Comment.ByID(ctx, c.f.comment.ID)
+				return err == nil && comment.Body == "Edited"
+			},
+		},
+		{
+			name: "hide comment", method: http.MethodPost, path: "/recipes/{recipe}/comments/{comment}/hide",
+			allowed: managers, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				comment, err := c.h.Services.Comment.ByID(ctx, c.f.comment.ID)
+				return err == nil && comment.Hidden
+			},
+		},
+		{
+			name: "delete comment", method: http.MethodPost, path: "/recipes/{recipe}/comments/{comment}/delete",
+			allowed: []role{roleOwner, roleWorkspaceOwner, roleViewer}, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.Comment.ByID(ctx, c.f.comment.ID)
+				return err == models.ErrNotFound
+			},
+		},
+		{
+			name: "upload images", method: http.MethodPost, path: "/recipes/{recipe}/images",
+			field:   "images",
+			files:   []harness.File{{Name: "crumb.jpg", Content: []byte("crumb")}},
+			allowed: editors, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return hasImage(c.h, c.f.recipe.ID, "crumb.jpg")
+			},
+		},
+		{
+			name: "delete image", method: http.MethodPost, path: "/recipes/{recipe}/images/bread.jpg/delete",
+			allowed: editors, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return !hasImage(c.h, c.f.recipe.ID, "bread.jpg")
+			},
+		},
+		{
+			name: "nutrition", method: http.MethodGet, path: "/recipes/{recipe}/nutrition",
+			allowed: editors, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`action="/recipes/{recipe}/nutrition"`))
+			},
+		},
+		{
+			name: "update nutrition", method: http.MethodPost, path: "/recipes/{recipe}/nutrition",
+			form:    url.Values{"matches.0.ingredient": {"flour"}, "matches.0.food_id": {"all_purpose_flour"}},
+			allowed: editors, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				matches, _ := c.h.Services.Nutrition.ByRecipeID(ctx, c.f.recipe.ID)
+				return len(matches) > 0
+			},
+		},
+	}
+
+	runRouteTests(t, tests)
+}
+
+func TestWorkspaceOwnerModeratesCookLogs(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	h.UseSession(string(roleWorkspaceOwner))
+
+	res := h.Get(f.path("/recipes/{recipe}"))
+	if !strings.Contains(res.Body, f.path(`action="/recipes/{recipe}/cooklogs/{cooklog}/delete"`)) {
+		t.Fatalf("recipe page does not offer the workspace owner a cook log delete button\n%s", res.Body)
+	}
+
+	h.PostForm("/recipes/new", f.path("/recipes/{recipe}/cooklogs/{cooklog}/delete"), nil)
+	if _, err := h.Services.CookLog.ByID(context.Background(), f.cookLog.ID); err != models.ErrNotFound {
+		t.Fatalf("cook log after moderation: err = %v, want %v", err, models.ErrNotFound)
+	}
+}
+
+func TestNestedRoutesRejectOtherRecipes(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	other := newFixture(t, h, users)
+	h.UseSession(string(roleOwner))
+
+	for _, pattern := range []string{
+		"/recipes/%d/comments/%d",
+		"/recipes/%d/comments/%d/hide",
+		"/recipes/%d/comments/%d/delete",
+	} {
+		path := fmt.Sprintf(pattern, other.recipe.ID, f.comment.ID)
+		if res := h.PostForm("/recipes/new", path, url.Values{"body": {"Edited"}}); res.StatusCode != http.StatusNotFound {
+			t.Errorf("POST %s = %d, want %d", path, res.StatusCode, http.StatusNotFound)
+		}
+	}
+
+	path := fmt.Sprintf("/recipes/%d/cooklogs/%d/delete", other.recipe.ID, f.cookLog.ID)
+	if res := h.PostForm("/recipes/new", path, nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("POST %s = %d, want %d", path, res.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestForkHidesUnviewableOriginal(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	ctx := context.Background()
+
+	fork := models.Recipe{UserID: users[roleViewer].ID, ForkedFromID: &f.recipe.ID, Title: "My Bread"}
+	if err := h.Services.Recipe.Create(ctx, &fork); err != nil {
+		t.Fatalf("create fork: %v", err)
+	}
+	h.UseSession(string(roleViewer))
+	link := f.path(`Adapted from <a href="/recipes/{recipe}">`)
+	forkPath := fmt.Sprintf("/recipes/%d", fork.ID)
+
+	if res := h.Get(forkPath); !strings.Contains(res.Body, link) {
+		t.Fatalf("fork page does not link a viewable original\n%s", res.Body)
+	}
+
+	if err := h.Services.Workspace.RemoveMembership(ctx, f.workspace.ID, users[roleViewer].ID); err != nil {
+		t.Fatalf("remove viewer: %v", err)
+	}
+	if res := h.Get(forkPath); res.StatusCode != http.StatusOK || strings.Contains(res.Body, "Adapted from") {
+		t.Fatalf("fork page = %d and links an original the viewer cannot see\n%s", res.StatusCode, res.Body)
+	}
+}
+
+func TestCollectionsHideUnviewableRecipes(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	ctx := context.Background()
+
+	collection := models.Collection{UserID: users[roleViewer].ID, Title: "Weeknights"}
+	if err := h.Services.Collection.Create(ctx, &collection); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+	if err := h.Services.Collection.AddRecipe(ctx, collection.ID, f.recipe.ID); err != nil {
+		t.Fatalf("add recipe: %v", err)
+	}
+	if err := h.Services.Workspace.RemoveMembership(ctx, f.workspace.ID, users[roleViewer].ID); err != nil {
+		t.Fatalf("remove viewer: %v", err)
+	}
+	h.UseSession(string(roleViewer))
+
+	for _, path := range []string{"/collections/%d", "/collections/%d/print"} {
+		path = fmt.Sprintf(path, collection.ID)
+		res := h.Get(path)
+		if res.StatusCode != http.StatusOK || strings.Contains(res.Body, f.recipe.Title) || strings.Contains(res.Body, "flour") {
+			t.Errorf("GET %s = %d and shows a recipe the viewer can no longer see\n%s", path, res.StatusCode, res.Body)
+		}
+	}
+}
+
+func runRouteTests(t *testing.T, tests []routeTest) {
+	t.Helper()
+
+	h, users := newHarness(t)
+
+	for _, tt := range tests {
+		for _, r := range roles {
+			f := newFixture(t, h, users)
+			path := f.path(tt.path)
+
+			form := url.Values{}
+			for k, vs := range tt.form {
+				for _, v := range vs {
+					form.Add(k, f.path(v))
+				}
+			}
+
+			h.UseSession(string(r))
+			var res *harness.Response
+			switch {
+			case tt.method == http.MethodGet:
+				res = h.Get(path)
+			case tt.field != "":
+				res = h.PostMultipart("/recipes/new", path, form, tt.field, tt.files...)
+			default:
+				res = h.PostForm("/recipes/new", path, form)
+			}
+
+			allowed := contains(tt.allowed, r)
+			want := tt.denied
+			if allowed {
+				want = http.StatusOK
+			}
+			if res.StatusCode != want {
+				t.Errorf("%s: %s %s as %s = %d, want %d", tt.name, tt.method, path, r, res.StatusCode, want)
+			}
+			if got := tt.effect(routeCase{h: h, f: f, user: users[r], res: res}); got != allowed {
+				t.Errorf("%s: %s %s as %s took effect = %v, want %v", tt.name, tt.method, path, r, got, allowed)
+			}
+		}
+	}
+}
+
+func newHarness(t *testing.T) (*harness.Harness, map[role]*models.User) {
+	t.Helper()
+
+	h := harness.New(t, "")
+	ctx := context.Background()
+
+	users := make(map[role]*models.User)
+	for _, r := range roles {
+		user := &models.User{Name: string(r), Email: emails[r], Password: password}
+		if err := h.Services.User.Create(ctx, user); err != nil {
+			t.Fatalf("create %s: %v", r, err)
+		}
+		users[r] = user
+
+		h.UseSession(string(r))
+		h.SignIn(emails[r], password)
+	}
+	return h, users
+}
+
+func newFixture(t *testing.T, h *harness.Harness, users map[role]*models.User) *fixture {
+	t.Helper()
+
+	ctx := context.Background()
+	s := h.Services
+	f := &fixture{}
+
+	f.workspace = models.Workspace{UserID: users[roleOwner].ID, Name: "Kitchen"}
+	if err := s.Workspace.Create(ctx, &f.workspace); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	for r, memberRole := range map[role]models.Role{
+		roleWorkspaceOwner: models.RoleOwner,
+		roleEditor:         models.RoleEditor,
+		roleViewer:         models.RoleViewer,
+	} {
+		err := s.Workspace.SaveMembership(ctx, &models.Membership{WorkspaceID: f.workspace.ID, UserID: users[r].ID, Role: memberRole})
+		if err != nil {
+			t.Fatalf("add %s: %v", r, err)
+		}
+	}
+
+	f.bakery = models.Workspace{UserID: users[roleOwner].ID, Name: "Bakery"}
+	if err := s.Workspace.Create(ctx, &f.bakery); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	for _, r := range []role{roleWorkspaceOwner, roleEditor} {
+		err := s.Workspace.SaveMembership(ctx, &models.Membership{WorkspaceID: f.bakery.ID, UserID: users[r].ID, Role: models.RoleEditor})
+		if err != nil {
+			t.Fatalf("add %s: %v", r, err)
+		}
+	}
+
+	f.recipe = models.Recipe{UserID: users[roleOwner].ID, WorkspaceID: &f.workspace.ID, Title: "Bread", Ingredients: "2 cups flour"}
+	if err := s.Recipe.Create(ctx, &f.recipe); err != nil {
+		t.Fatalf("create recipe: %v", err)
+	}
+	if err := s.Image.Create(ctx, f.recipe.ID, strings.NewReader("bread"), "bread.jpg"); err != nil {
+		t.Fatalf("create image: %v", err)
+	}
+	f.duplicate = models.Recipe{UserID: users[roleOwner].ID, WorkspaceID: &f.workspace.ID, Title: "Bread", Ingredients: "2 cups flour"}
+	if err := s.Recipe.Create(ctx, &f.duplicate); err != nil {
+		t.Fatalf("create duplicate: %v", err)
+	}
+	f.personal = models.Recipe{UserID: users[roleOwner].ID, Title: "Rolls", Ingredients: "1 cup milk"}
+	if err := s.Recipe.Create(ctx, &f.personal); err != nil {
+		t.Fatalf("create personal recipe: %v", err)
+	}
+
+	f.comment = models.Comment{RecipeID: f.recipe.ID, UserID: users[roleViewer].ID, Body: "Too salty"}
+	if err := s.Comment.Create(ctx, &f.comment); err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	f.cookLog = models.CookLog{RecipeID: f.recipe.ID, UserID: users[roleViewer].ID, CookedOn: time.Now(), Rating: 3}
+	if err := s.CookLog.Create(ctx, &f.cookLog); err != nil {
+		t.Fatalf("create cook log: %v", err)
+	}
+
+	owner := users[roleOwner].ID
+	f.viewerID = users[roleViewer].ID
+
+	invitation, err := s.Workspace.Invite(ctx, f.workspace.ID, owner, models.RoleViewer)
+	if err != nil {
+		t.Fatalf("invite: %v", err)
+	}
+	f.invitation = invitation
+
+	f.collection = models.Collection{UserID: owner, Title: "Weeknights"}
+	if err := s.Collection.Create(ctx, &f.collection); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+	for _, recipe := range []models.Recipe{f.recipe, f.duplicate} {
+		if err := s.Collection.AddRecipe(ctx, f.collection.ID, recipe.ID); err != nil {
+			t.Fatalf("add recipe to collection: %v", err)
+		}
+	}
+
+	f.entry = models.MealPlanEntry{UserID: owner, RecipeID: f.recipe.ID, Date: time.Now(), Slot: models.MealSlotDinner}
+	if err := s.MealPlan.Create(ctx, &f.entry); err != nil {
+		t.Fatalf("create meal plan entry: %v", err)
+	}
+
+	f.list = models.ShoppingList{UserID: owner, Title: "Groceries"}
+	f.list.AddIngredients(f.recipe.ScaledIngredients(0))
+	if err := s.ShoppingList.Create(ctx, &f.list); err != nil {
+		t.Fatalf("create shopping list: %v", err)
+	}
+
+	f.pantryItem = models.PantryItem{UserID: owner, Name: "milk", Quantity: 1, Unit: "cup"}
+	if err := s.Pantry.Create(ctx, &f.pantryItem); err != nil {
+		t.Fatalf("create pantry item: %v", err)
+	}
+
+	return f
+}
+
+func contains(roles []role, r role) bool {
+	for _, allowed := range roles {
+		if allowed == r {
+			return true
+		}
+	}
+	return false
+}
+
+func hasImage(h *harness.Harness, recipeID uint, filename string) bool {
+	images, _ := h.Services.Image.ByRecipeID(context.Background(), recipeID)
+	for _, image := range images {
+		if image.Filename == filename {
+			return true
+		}
+	}
+	return false
+}
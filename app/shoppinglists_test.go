@@ -0,0 +1,78 @@
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+func TestShoppingListRoutes(t *testing.T) {
+	owner := []role{roleOwner}
+	everyMember := []role{roleOwner, roleWorkspaceOwner, roleEditor, roleViewer}
+	ctx := context.Background()
+
+	runRouteTests(t, []routeTest{
+		{
+			name: "index", method: http.MethodGet, path: "/shoppinglists",
+			allowed: owner, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/shoppinglists/{list}"`))
+			},
+		},
+		{
+			name: "create", method: http.MethodPost, path: "/shoppinglists",
+			form:    url.Values{"title": {"Party"}, "recipes.0.id": {"{recipe}"}, "recipes.0.selected": {"true"}},
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				lists, _ := c.h.Services.ShoppingList.ByUserID(ctx, c.user.ID)
+				for _, list := range lists {
+					if list.Title == "Party" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "new", method: http.MethodGet, path: "/shoppinglists/new",
+			allowed: roles,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, `action="/shoppinglists"`)
+			},
+		},
+		{
+			name: "show", method: http.MethodGet, path: "/shoppinglists/{list}",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`action="/shoppinglists/{list}/items/{item}/toggle"`))
+			},
+		},
+		{
+			name: "export", method: http.MethodGet, path: "/shoppinglists/{list}/export?format=markdown",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, "flour")
+			},
+		},
+		{
+			name: "toggle item", method: http.MethodPost, path: "/shoppinglists/{list}/items/{item}/toggle",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				list, err := c.h.Services.ShoppingList.ByID(ctx, c.f.list.ID)
+				return err == nil && list.Items[0].Checked
+			},
+		},
+		{
+			name: "delete", method: http.MethodPost, path: "/shoppinglists/{list}/delete",
+			allowed: owner, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.ShoppingList.ByID(ctx, c.f.list.ID)
+				return err == models.ErrNotFound
+			},
+		},
+	})
+}
@@ -0,0 +1,126 @@
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+func TestWorkspaceRoutes(t *testing.T) {
+	everyMember := []role{roleOwner, roleWorkspaceOwner, roleEditor, roleViewer}
+	managers := []role{roleOwner, roleWorkspaceOwner}
+	ctx := context.Background()
+
+	runRouteTests(t, []routeTest{
+		{
+			name: "index", method: http.MethodGet, path: "/workspaces",
+			allowed: everyMember, denied: http.StatusOK,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/workspaces/{workspace}"`))
+			},
+		},
+		{
+			name: "create", method: http.MethodPost, path: "/workspaces",
+			form:    url.Values{"name": {"Pastry"}},
+			allowed: roles,
+			effect: func(c routeCase) bool {
+				workspaces, _ := c.h.Services.Workspace.ByUserID(ctx, c.user.ID)
+				for _, workspace := range workspaces {
+					if workspace.Name == "Pastry" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "new", method: http.MethodGet, path: "/workspaces/new",
+			allowed: roles,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, `action="/workspaces"`)
+			},
+		},
+		{
+			name: "show", method: http.MethodGet, path: "/workspaces/{workspace}",
+			allowed: everyMember, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, c.f.path(`href="/recipes/{recipe}"`))
+			},
+		},
+		{
+			name: "update", method: http.MethodPost, path: "/workspaces/{workspace}",
+			form:    url.Values{"name": {"Galley"}},
+			allowed: managers, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				workspace, err := c.h.Services.Workspace.ByID(ctx, c.f.workspace.ID)
+				return err == nil && workspace.Name == "Galley"
+			},
+		},
+		{
+			name: "invite", method: http.MethodPost, path: "/workspaces/{workspace}/invitations",
+			form:    url.Values{"role": {string(models.RoleEditor)}},
+			allowed: managers, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, "Invite link created for a new editor")
+			},
+		},
+		{
+			name: "update member", method: http.MethodPost, path: "/workspaces/{workspace}/members/{viewer}",
+			form:    url.Values{"role": {string(models.RoleEditor)}},
+			allowed: managers, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				membership, err := c.h.Services.Workspace.Membership(ctx, c.f.workspace.ID, c.f.viewerID)
+				return err == nil && membership.Role == models.RoleEditor
+			},
+		},
+		{
+			name: "remove member", method: http.MethodPost, path: "/workspaces/{workspace}/members/{viewer}/delete",
+			allowed: []role{roleOwner, roleWorkspaceOwner, roleViewer}, denied: http.StatusNotFound,
+			effect: func(c routeCase) bool {
+				_, err := c.h.Services.Workspace.Membership(ctx, c.f.workspace.ID, c.f.viewerID)
+				return err == models.ErrNotFound
+			},
+		},
+		{
+			name: "invitation", method: http.MethodGet, path: "/invitations/{token}",
+			allowed: roles,
+			effect: func(c routeCase) bool {
+				return strings.Contains(c.res.Body, "Join Kitchen")
+			},
+		},
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	h, users := newHarness(t)
+	f := newFixture(t, h, users)
+	ctx := context.Background()
+	h.UseSession(string(roleStranger))
+
+	path := f.path("/invitations/{token}")
+	res := h.PostForm(path, path, nil)
+	if want := f.path("/workspaces/{workspace}"); res.StatusCode != http.StatusOK || res.URL.Path != want {
+		t.Fatalf("POST %s = %d %s, want %d %s", path, res.StatusCode, res.URL.Path, http.StatusOK, want)
+	}
+
+	membership, err := h.Services.Workspace.Membership(ctx, f.workspace.ID, users[roleStranger].ID)
+	if err != nil {
+		t.Fatalf("Membership: %v", err)
+	}
+	if membership.Role != models.RoleViewer {
+		t.Errorf("membership role = %q, want %q", membership.Role, models.RoleViewer)
+	}
+	if res := h.Get(f.path("/recipes/{recipe}")); res.StatusCode != http.StatusOK {
+		t.Errorf("GET shared recipe after joining = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	h.UseSession(string(roleEditor))
+	res = h.PostForm("/workspaces/new", path, nil)
+	if alert := models.ErrInvitationInvalid.Alert(); !strings.Contains(res.Body, alert) {
+		t.Errorf("reusing an accepted invitation does not show %q\n%s", alert, res.Body)
+	}
+}
@@ -0,0 +1,6 @@
+package assets
+
+import "embed"
+
+//go:embed *.css *.js
+var FS embed.FS
@@ -0,0 +1,32 @@
+(function () {
+  var csrfInput = document.querySelector('#updateRecipeForm input[name="gorilla.csrf.Token"]');
+
+  document.querySelectorAll('[data-markdown-preview]').forEach(function (textarea) {
+    var preview = document.getElementById(textarea.dataset.markdownPreview);
+    var timer;
+
+    textarea.addEventListener('input', function () {
+      clearTimeout(timer);
+      timer = setTimeout(function () {
+        var body = new URLSearchParams();
+        body.append('source', textarea.value);
+        body.append('gorilla.csrf.Token', csrfInput.value);
+
+        fetch('/recipes/preview', {
+          method: 'POST',
+          credentials: 'same-origin',
+          body: body
+        }).then(function (response) {
+          if (!response.ok) {
+            throw new Error(response.statusText);
+          }
+          return response.text();
+        }).then(function (html) {
+          preview.innerHTML = html;
+        }).catch(function () {
+          preview.textContent = 'Preview unavailable';
+        });
+      }, 300);
+    });
+  });
+})();
@@ -0,0 +1,46 @@
+(function () {
+  var csrfInput = document.querySelector('#mealPlanMoveForm input[name="gorilla.csrf.Token"]');
+
+  document.querySelectorAll('.meal-plan-entry').forEach(function (entry) {
+    entry.addEventListener('dragstart', function (event) {
+      event.dataTransfer.setData('text/plain', entry.dataset.entryId);
+      event.dataTransfer.effectAllowed = 'move';
+    });
+  });
+
+  document.querySelectorAll('.meal-plan-slot').forEach(function (slot) {
+    slot.addEventListener('dragover', function (event) {
+      event.preventDefault();
+      slot.classList.add('table-active');
+    });
+
+    slot.addEventListener('dragleave', function () {
+      slot.classList.remove('table-active');
+    });
+
+    slot.addEventListener('drop', function (event) {
+      event.preventDefault();
+      slot.classList.remove('table-active');
+
+      var entryID = event.dataTransfer.getData('text/plain');
+      if (!entryID) {
+        return;
+      }
+
+      var body = new URLSearchParams();
+      body.set('date', slot.dataset.date);
+      body.set('slot', slot.dataset.slot);
+      if (csrfInput) {
+        body.set(csrfInput.name, csrfInput.value);
+      }
+
+      fetch('/mealplan/entries/' + entryID, {
+        method: 'POST',
+        body: body,
+        credentials: 'same-origin'
+      }).then(function () {
+        window.location.reload();
+      });
+    });
+  });
+})();
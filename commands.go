@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/rand"
+)
+
+var (
+	errUserUsage    = errors.New("usage: gocookit user create|list|disable|reset-password [flags]")
+	errRecipeUsage  = errors.New("usage: gocookit recipe reassign --from <user> --to <user>")
+	errImagesUsage  = errors.New("usage: gocookit images reconcile [--delete]")
+	errDBUsage      = errors.New("usage: gocookit db reset [--yes]")
+	errConfigUsage  = errors.New("usage: gocookit config print [--redacted]")
+	errDBResetAbort = errors.New("database reset aborted")
+)
+
+func runUser(ctx context.Context, services *models.Services, args []string) error {
+	if len(args) == 0 {
+		return errUserUsage
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("user create", flag.ExitOnError)
+		name := fs.String("name", "", "Name of the new user")
+		email := fs.String("email", "", "Email address of the new user")
+		password := fs.String("password", "", "Password of the new user; one is generated when omitted")
+		fs.Parse(args[1:])
+
+		pw, generated, err := passwordOrGenerate(*password)
+		if err != nil {
+			return err
+		}
+
+		user := models.User{Name: *name, Email: *email, Password: pw}
+		if err := services.User.Create(ctx, &user); err != nil {
+			return err
+		}
+
+		fmt.Printf("Created user %d <%s>\n", user.ID, user.Email)
+		if generated {
+			fmt.Printf("Password: %s\n", pw)
+		}
+	case "list":
+		users, err := services.User.All(ctx)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			state := "active"
+			if user.Disabled {
+				state = "disabled"
+			}
+			fmt.Printf("%6d  %-30s %-40s %s\n", user.ID, user.Name, user.Email, state)
+		}
+	case "disable":
+		fs := flag.NewFlagSet("user disable", flag.ExitOnError)
+		ref := fs.String("user", "", "ID or email address of the user")
+		fs.Parse(args[1:])
+
+		user, err := findUser(ctx, services.User, *ref)
+		if err != nil {
+			return err
+		}
+
+		user.Disabled = true
+		if err := rotateRemember(user); err != nil {
+			return err
+		}
+		if err := services.User.Update(ctx, user); err != nil {
+			return err
+		}
+
+		fmt.Printf("Disabled user %d <%s>\n", user.ID, user.Email)
+	case "reset-password":
+		fs := flag.NewFlagSet("user reset-password", flag.ExitOnError)
+		ref := fs.String("user", "", "ID or email address of the user")
+		password := fs.String("password", "", "New password; one is generated when omitted")
+		fs.Parse(args[1:])
+
+		user, err := findUser(ctx, services.User, *ref)
+		if err != nil {
+			return err
+		}
+
+		pw, generated, err := passwordOrGenerate(*password)
+		if err != nil {
+			return err
+		}
+
+		user.Password = pw
+		if err := rotateRemember(user); err != nil {
+			return err
+		}
+		if err := services.User.Update(ctx, user); err != nil {
+			return err
+		}
+
+		fmt.Printf("Reset password for user %d <%s>\n", user.ID, user.Email)
+		if generated {
+			fmt.Printf("Password: %s\n", pw)
+		}
+	default:
+		return errUserUsage
+	}
+
+	return nil
+}
+
+func runRecipe(ctx context.Context, services *models.Services, args []string) error {
+	if len(args) == 0 || args[0] != "reassign" {
+		return errRecipeUsage
+	}
+
+	fs := flag.NewFlagSet("recipe reassign", flag.ExitOnError)
+	fromRef := fs.String("from", "", "ID or email address of the current owner")
+	toRef := fs.String("to", "", "ID or email address of the new owner")
+	fs.Parse(args[1:])
+
+	from, err := findUser(ctx, services.User, *fromRef)
+	if err != nil {
+		return err
+	}
+	to, err := findUser(ctx, services.User, *toRef)
+	if err != nil {
+		return err
+	}
+
+	n, err := services.Recipe.Reassign(ctx, from.ID, to.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Reassigned %d recipe(s) from <%s> to <%s>\n", n, from.Email, to.Email)
+	return nil
+}
+
+func runImages(ctx context.Context, services *models.Services, args []string) error {
+	if len(args) == 0 || args[0] != "reconcile" {
+		return errImagesUsage
+	}
+
+	fs := flag.NewFlagSet("images reconcile", flag.ExitOnError)
+	remove := fs.Bool("delete", false, "Delete orphaned image and cook log photo directories")
+	fs.Parse(args[1:])
+
+	recipeIDs, err := services.Recipe.IDs(ctx)
+	if err != nil {
+		return err
+	}
+	recipes := make(map[uint]bool, len(recipeIDs))
+	for _, id := range recipeIDs {
+		recipes[id] = true
+	}
+
+	dirIDs, err := services.Image.RecipeIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var checked, orphanDirs int
+	for _, id := range dirIDs {
+		images, err := services.Image.ByRecipeID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if recipes[id] {
+			checked += len(images)
+			continue
+		}
+
+		orphanDirs++
+		if *remove {
+			if err := services.Image.DeleteRecipeDir(ctx, id); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %d orphaned image(s) for recipe %d\n", len(images), id)
+			continue
+		}
+		fmt.Printf("Recipe %d no longer exists but has %d image(s)\n", id, len(images))
+	}
+
+	cookLogs, err := services.CookLog.WithPhotos(ctx)
+	if err != nil {
+		return err
+	}
+	withPhotos := make(map[uint]bool, len(cookLogs))
+
+	var missing int
+	for _, cookLog := range cookLogs {
+		withPhotos[cookLog.ID] = true
+		photos, err := services.Image.PhotosByCookLogID(ctx, cookLog.ID)
+		if err != nil {
+			return err
+		}
+		photo := cookLog.PhotoImage()
+		if !containsPhoto(photos, *photo) {
+			missing++
+			fmt.Printf("Cook log %d is missing %s\n", cookLog.ID, photo.RelativePath())
+		}
+	}
+
+	photoDirIDs, err := services.Image.PhotoCookLogIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var orphanPhotoDirs int
+	for _, id := range photoDirIDs {
+		if withPhotos[id] {
+			continue
+		}
+
+		orphanPhotoDirs++
+		if *remove {
+			if err := services.Image.DeletePhotoDir(ctx, id); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted orphaned photos for cook log %d\n", id)
+			continue
+		}
+		fmt.Printf("Cook log %d no longer exists but has photos\n", id)
+	}
+
+	fmt.Printf("Checked %d recipe images: %d orphaned image directories, %d orphaned cook log photo directories, %d missing cook log photos\n",
+		checked, orphanDirs, orphanPhotoDirs, missing)
+	return nil
+}
+
+func containsPhoto(photos []models.Photo, photo models.Photo) bool {
+	for _, p := range photos {
+		if p == photo {
+			return true
+		}
+	}
+	return false
+}
+
+func runDB(ctx context.Context, services *models.Services, args []string) error {
+	if len(args) == 0 || args[0] != "reset" {
+		return errDBUsage
+	}
+
+	fs := flag.NewFlagSet("db reset", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "Skip the confirmation prompt")
+	fs.Parse(args[1:])
+
+	if !*yes {
+		fmt.Print("This drops every table and all data. Type \"reset\" to continue: ")
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return errDBResetAbort
+		}
+		if strings.TrimSpace(answer) != "reset" {
+			return errDBResetAbort
+		}
+	}
+
+	if err := services.DestructiveReset(ctx); err != nil {
+		return err
+	}
+
+	fmt.Println("Database reset")
+	return nil
+}
+
+func runConfig(cfg Config, loadErr error, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errConfigUsage
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	redact := fs.Bool("redacted", false, "Replace secrets with a placeholder")
+	fs.Parse(args[1:])
+
+	if *redact {
+		cfg = cfg.Redacted()
+	}
+
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+
+	return loadErr
+}
+
+func findUser(ctx context.Context, us models.UserService, ref string) (*models.User, error) {
+	if ref == "" {
+		return nil, models.ErrNotFound
+	}
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		return us.ByID(ctx, uint(id))
+	}
+	return us.ByEmail(ctx, ref)
+}
+
+func passwordOrGenerate(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+
+	b, err := rand.Bytes(12)
+	if err != nil {
+		return "", false, err
+	}
+	return base64.URLEncoding.EncodeToString(b), true, nil
+}
+
+func rotateRemember(user *models.User) error {
+	token, err := rand.RememberToken()
+	if err != nil {
+		return err
+	}
+	user.Remember = token
+	return nil
+}
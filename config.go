@@ -2,9 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type PostgresConfig struct {
@@ -39,39 +43,265 @@ type Config struct {
 	Pepper   string         `json:"pepper"`
 	HMACKey  string         `json:"hmac_key"`
 	Database PostgresConfig `json:"database"`
+
+	AutoMigrate             bool `json:"auto_migrate"`
+	RecommendationsInterval int  `json:"recommendations_interval_minutes"`
+	ReloadTemplates         bool `json:"reload_templates"`
+
+	TLSCert         string `json:"tls_cert"`
+	TLSKey          string `json:"tls_key"`
+	RedirectPort    int    `json:"redirect_port"`
+	ShutdownTimeout int    `json:"shutdown_timeout_seconds"`
+	QueryTimeout    int    `json:"query_timeout_seconds"`
 }
 
 func DefaultConfig() Config {
 	return Config{
 		Port:     8000,
-		Env:      "dev",
+		Env:      envDev,
 		Pepper:   "pepper",
 		HMACKey:  "secret",
 		Database: DefaultPostgresConfig(),
+
+		AutoMigrate:             true,
+		RecommendationsInterval: 60,
+
+		ShutdownTimeout: 30,
+		QueryTimeout:    5,
 	}
 }
 
 func (c Config) IsProd() bool {
-	return c.Env == "prod"
+	return c.Env == envProd
+}
+
+func (c Config) TLSEnabled() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
+const (
+	envDev  = "dev"
+	envProd = "prod"
+
+	envPrefix         = "GOCOOKIT_"
+	defaultConfigPath = ".config"
+	minSecretLength   = 32
+	redacted          = "REDACTED"
+)
+
+var weakSecrets = map[string]bool{
+	"":         true,
+	"secret":   true,
+	"pepper":   true,
+	"password": true,
+	"changeme": true,
+}
+
+type configField struct {
+	name  string
+	usage string
+	set   func(*Config, string) error
+}
+
+var configFields = []configField{
+	{"port", "Port the HTTP server listens on", func(c *Config, v string) error { return setInt(&c.Port, v) }},
+	{"env", "Environment, either dev or prod", func(c *Config, v string) error { c.Env = v; return nil }},
+	{"pepper", "Pepper appended to passwords before hashing", func(c *Config, v string) error { c.Pepper = v; return nil }},
+	{"hmac-key", "Key used to hash remember and invite tokens", func(c *Config, v string) error { c.HMACKey = v; return nil }},
+	{"auto-migrate", "Run pending migrations when the server starts", func(c *Config, v string) error { return setBool(&c.AutoMigrate, v) }},
+	{"recommendations-interval-minutes", "Minutes between recommendation rebuilds, 0 disables them", func(c *Config, v string) error { return setInt(&c.RecommendationsInterval, v) }},
+	{"reload-templates", "Serve templates and assets from disk and re-parse templates on every request (dev only)", func(c *Config, v string) error { return setBool(&c.ReloadTemplates, v) }},
+	{"tls-cert", "Path to the TLS certificate; enables HTTPS together with -tls-key", func(c *Config, v string) error { c.TLSCert = v; return nil }},
+	{"tls-key", "Path to the TLS private key", func(c *Config, v string) error { c.TLSKey = v; return nil }},
+	{"redirect-port", "Port that redirects plain HTTP to HTTPS when TLS is enabled, 0 disables it", func(c *Config, v string) error { return setInt(&c.RedirectPort, v) }},
+	{"shutdown-timeout-seconds", "Seconds to wait for in-flight requests on shutdown", func(c *Config, v string) error { return setInt(&c.ShutdownTimeout, v) }},
+	{"query-timeout-seconds", "Seconds a single database query may run, 0 disables the limit", func(c *Config, v string) error { return setInt(&c.QueryTimeout, v) }},
+	{"db-host", "Postgres host", func(c *Config, v string) error { c.Database.Host = v; return nil }},
+	{"db-port", "Postgres port", func(c *Config, v string) error { return setInt(&c.Database.Port, v) }},
+	{"db-user", "Postgres user", func(c *Config, v string) error { c.Database.User = v; return nil }},
+	{"db-password", "Postgres password", func(c *Config, v string) error { c.Database.Password = v; return nil }},
+	{"db-name", "Postgres database name", func(c *Config, v string) error { c.Database.Name = v; return nil }},
+}
+
+type ConfigFlags struct {
+	fs   *flag.FlagSet
+	path *string
+	prod *bool
+}
+
+func RegisterConfigFlags(fs *flag.FlagSet) *ConfigFlags {
+	cf := &ConfigFlags{
+		fs:   fs,
+		path: fs.String("config", defaultConfigPath, "Path to a JSON config file"),
+		prod: fs.Bool("prod", false, "Shorthand for -env=prod, which enables strict config validation"),
+	}
+	for _, field := range configFields {
+		fs.String(field.name, "", field.usage+" (env "+field.envVar()+")")
+	}
+	return cf
+}
+
+func (f configField) envVar() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(f.name, "-", "_"))
+}
+
+func LoadConfig(cf *ConfigFlags) (Config, error) {
+	config := DefaultConfig()
+
+	explicitPath := false
+	cf.fs.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			explicitPath = true
+		}
+	})
+
+	if err := config.loadFile(*cf.path, explicitPath); err != nil {
+		return config, err
+	}
+
+	for _, field := range configFields {
+		v, ok := os.LookupEnv(field.envVar())
+		if !ok {
+			continue
+		}
+		if err := field.set(&config, v); err != nil {
+			return config, fmt.Errorf("%s: %w", field.envVar(), err)
+		}
+	}
+
+	var err error
+	cf.fs.Visit(func(f *flag.Flag) {
+		for _, field := range configFields {
+			if err == nil && field.name == f.Name {
+				if setErr := field.set(&config, f.Value.String()); setErr != nil {
+					err = fmt.Errorf("-%s: %w", field.name, setErr)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return config, err
+	}
+
+	if *cf.prod {
+		config.Env = envProd
+	}
+
+	if !config.IsProd() && (weakSecrets[config.Pepper] || weakSecrets[config.HMACKey]) {
+		log.Println("Using insecure development secrets; set GOCOOKIT_PEPPER and GOCOOKIT_HMAC_KEY to override them")
+	}
+
+	return config, config.Validate()
 }
 
-func LoadConfig(configRequired bool) Config {
-	f, err := os.Open(".config")
+func (c *Config) loadFile(path string, required bool) error {
+	f, err := os.Open(path)
 	if err != nil {
-		if configRequired {
-			panic(err)
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	log.Printf("Loaded config file %s\n", path)
+	return nil
+}
+
+func (c Config) Validate() error {
+	var problems []string
+
+	if c.Env != envDev && c.Env != envProd {
+		problems = append(problems, fmt.Sprintf("env must be %q or %q", envDev, envProd))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		problems = append(problems, "port must be between 1 and 65535")
+	}
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		problems = append(problems, "database port must be between 1 and 65535")
+	}
+	if c.Database.Host == "" {
+		problems = append(problems, "database host is required")
+	}
+	if c.Database.Name == "" {
+		problems = append(problems, "database name is required")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		problems = append(problems, "tls cert and tls key must be set together")
+	}
+	if c.RedirectPort != 0 {
+		if !c.TLSEnabled() {
+			problems = append(problems, "redirect port requires tls")
+		}
+		if c.RedirectPort < 0 || c.RedirectPort > 65535 || c.RedirectPort == c.Port {
+			problems = append(problems, "redirect port must be between 1 and 65535 and differ from port")
 		}
+	}
+	if c.ReloadTemplates && c.IsProd() {
+		problems = append(problems, "reload templates is only allowed in dev")
+	}
+	if c.ShutdownTimeout <= 0 {
+		problems = append(problems, "shutdown timeout must be positive")
+	}
+	if c.QueryTimeout < 0 {
+		problems = append(problems, "query timeout cannot be negative")
+	}
+	if c.RecommendationsInterval < 0 {
+		problems = append(problems, "recommendations interval cannot be negative")
+	}
 
-		log.Println("Using the default config...")
-		return DefaultConfig()
+	if c.IsProd() {
+		if weakSecrets[c.Pepper] || len(c.Pepper) < minSecretLength {
+			problems = append(problems, fmt.Sprintf("pepper must be a non-default secret of at least %d characters in prod", minSecretLength))
+		}
+		if weakSecrets[c.HMACKey] || len(c.HMACKey) < minSecretLength {
+			problems = append(problems, fmt.Sprintf("hmac key must be a non-default secret of at least %d characters in prod", minSecretLength))
+		}
+		if c.HMACKey != "" && c.HMACKey == c.Pepper {
+			problems = append(problems, "hmac key and pepper must differ")
+		}
 	}
 
-	var config Config
-	err = json.NewDecoder(f).Decode(&config)
+	if len(problems) > 0 {
+		return errors.New("invalid config: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (c Config) Redacted() Config {
+	redact := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return redacted
+	}
+
+	c.Pepper = redact(c.Pepper)
+	c.HMACKey = redact(c.HMACKey)
+	c.Database.Password = redact(c.Database.Password)
+	return c
+}
+
+func setInt(dst *int, v string) error {
+	n, err := strconv.Atoi(v)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	*dst = n
+	return nil
+}
 
-	log.Println("Successfully loadded .config file")
-	return config
+func setBool(dst *bool, v string) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*dst = b
+	return nil
 }
@@ -2,6 +2,7 @@ package context
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/mpanelo/gocookit/models"
 )
@@ -9,9 +10,18 @@ import (
 type contextKey string
 
 const (
-	userKey = contextKey("user")
+	userKey        = contextKey("user")
+	recipeKey      = contextKey("recipe")
+	requestInfoKey = contextKey("request_info")
+	loggerKey      = contextKey("logger")
 )
 
+type RequestInfo struct {
+	ID     string
+	Route  string
+	UserID uint
+}
+
 func WithUser(ctx context.Context, user *models.User) context.Context {
 	return context.WithValue(ctx, userKey, user)
 }
@@ -26,3 +36,54 @@ func User(ctx context.Context) *models.User {
 	}
 	return nil
 }
+
+func WithRecipe(ctx context.Context, recipe *models.Recipe) context.Context {
+	return context.WithValue(ctx, recipeKey, recipe)
+}
+
+func Recipe(ctx context.Context) *models.Recipe {
+	if value := ctx.Value(recipeKey); value != nil {
+		recipe, ok := value.(*models.Recipe)
+		if ok {
+			return recipe
+		}
+		return nil
+	}
+	return nil
+}
+
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey, info)
+}
+
+func RequestInfoFrom(ctx context.Context) *RequestInfo {
+	if value := ctx.Value(requestInfoKey); value != nil {
+		info, ok := value.(*RequestInfo)
+		if ok {
+			return info
+		}
+		return nil
+	}
+	return nil
+}
+
+func RequestID(ctx context.Context) string {
+	if info := RequestInfoFrom(ctx); info != nil {
+		return info.ID
+	}
+	return ""
+}
+
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+func Logger(ctx context.Context) *slog.Logger {
+	if value := ctx.Value(loggerKey); value != nil {
+		logger, ok := value.(*slog.Logger)
+		if ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
@@ -0,0 +1,385 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+const (
+	RouteCollectionShow = "routeCollectionShow"
+	RouteCollectionEdit = "routeCollectionEdit"
+)
+
+type Collections struct {
+	NewView   *views.View
+	EditView  *views.View
+	IndexView *views.View
+	ShowView  *views.View
+	PrintView *views.View
+	cs        models.CollectionService
+	rs        models.RecipeService
+	is        models.ImageService
+	policy    *policy.Policy
+	router    *mux.Router
+}
+
+func NewCollections(vs *views.Registry, cs models.CollectionService, rs models.RecipeService, is models.ImageService, p *policy.Policy, router *mux.Router) *Collections {
+	return &Collections{
+		NewView:   vs.NewView("collections/new", nil),
+		EditView:  vs.NewView("collections/edit", sampleCollection()),
+		IndexView: vs.NewView("collections/index", []models.Collection{*sampleCollection()}),
+		ShowView:  vs.NewView("collections/show", sampleCollection()),
+		PrintView: vs.NewView("collections/print", sampleCollection()),
+		cs:        cs,
+		rs:        rs,
+		is:        is,
+		policy:    p,
+		router:    router,
+	}
+}
+
+func (cc *Collections) Index(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	user := context.User(r.Context())
+
+	collections, err := cc.cs.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		cc.IndexView.Render(rw, r, vd)
+		return
+	}
+
+	vd.Yield = collections
+	cc.IndexView.Render(rw, r, vd)
+}
+
+type CollectionForm struct {
+	Title       string `schema:"title"`
+	Description string `schema:"description"`
+}
+
+func (cc *Collections) Create(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form CollectionForm
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		cc.NewView.Render(rw, r, vd)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	collection := models.Collection{
+		UserID:      user.ID,
+		Title:       form.Title,
+		Description: form.Description,
+	}
+
+	err := cc.cs.Create(r.Context(), &collection)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		cc.NewView.Render(rw, r, vd)
+		return
+	}
+
+	cc.redirect(rw, r, RouteCollectionShow, collection.ID)
+}
+
+func (cc *Collections) Show(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	collection, err := cc.getCollectionWithRecipes(rw, r)
+	if err != nil {
+		return
+	}
+
+	vd.Yield = collection
+	cc.ShowView.Render(rw, r, vd)
+}
+
+func (cc *Collections) Print(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	collection, err := cc.getCollectionWithRecipes(rw, r)
+	if err != nil {
+		return
+	}
+
+	vd.Yield = collection
+	cc.PrintView.Render(rw, r, vd)
+}
+
+func (cc *Collections) Edit(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	collection, err := cc.getCollection(rw, r)
+	if err != nil {
+		return
+	}
+
+	vd.Yield = collection
+	cc.EditView.Render(rw, r, vd)
+}
+
+func (cc *Collections) Update(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form CollectionForm
+
+	collection, err := cc.getCollection(rw, r)
+	if err != nil {
+		return
+	}
+
+	vd.Yield = collection
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		cc.EditView.Render(rw, r, vd)
+		return
+	}
+
+	collection.Title = form.Title
+	collection.Description = form.Description
+
+	err = cc.cs.Update(r.Context(), collection)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		cc.EditView.Render(rw, r, vd)
+		return
+	}
+
+	cc.redirect(rw, r, RouteCollectionShow, collection.ID)
+}
+
+func (cc *Collections) Delete(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	collection, err := cc.getCollection(rw, r)
+	if err != nil {
+		return
+	}
+
+	err = cc.cs.Delete(r.Context(), collection.ID)
+	if err != nil {
+		vd.Yield = collection
+		vd.SetAlertDanger(err)
+		cc.EditView.Render(rw, r, vd)
+		return
+	}
+
+	http.Redirect(rw, r, "/collections", http.StatusFound)
+}
+
+type CollectionRecipeForm struct {
+	RecipeID uint `schema:"recipe_id"`
+	Position int  `schema:"position"`
+}
+
+func (cc *Collections) AddRecipe(rw http.ResponseWriter, r *http.Request) {
+	var form CollectionRecipeForm
+
+	collection, err := cc.getCollection(rw, r)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid recipe", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := cc.getRecipe(rw, r, form.RecipeID); err != nil {
+		return
+	}
+
+	err = cc.cs.AddRecipe(r.Context(), collection.ID, form.RecipeID)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, views.AlertGenericMsg, http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(rw, r, cc.returnPath(r, collection.ID), http.StatusFound)
+}
+
+func (cc *Collections) RemoveRecipe(rw http.ResponseWriter, r *http.Request) {
+	collection, err := cc.getCollection(rw, r)
+	if err != nil {
+		return
+	}
+
+	recipeID, err := strconv.Atoi(mux.Vars(r)["recipeID"])
+	if err != nil {
+		http.Error(rw, "Invalid recipe ID", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	err = cc.cs.RemoveRecipe(r.Context(), collection.ID, uint(recipeID))
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, views.AlertGenericMsg, http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(rw, r, cc.returnPath(r, collection.ID), http.StatusFound)
+}
+
+func (cc *Collections) MoveRecipe(rw http.ResponseWriter, r *http.Request) {
+	var form CollectionRecipeForm
+
+	collection, err := cc.getCollection(rw, r)
+	if err != nil {
+		return
+	}
+
+	recipeID, err := strconv.Atoi(mux.Vars(r)["recipeID"])
+	if err != nil {
+		http.Error(rw, "Invalid recipe ID", http.StatusNotFound)
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid position", http.StatusBadRequest)
+		return
+	}
+
+	err = cc.cs.MoveRecipe(r.Context(), collection.ID, uint(recipeID), form.Position)
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Recipe not found", http.StatusNotFound)
+			return
+		}
+		logError(r, err)
+		http.Error(rw, views.AlertGenericMsg, http.StatusInternalServerError)
+		return
+	}
+
+	cc.redirect(rw, r, RouteCollectionShow, collection.ID)
+}
+
+func (cc *Collections) returnPath(r *http.Request, collectionID uint) string {
+	if recipeID := r.PostForm.Get("return_recipe_id"); recipeID != "" {
+		url, err := cc.router.Get(RouteRecipeShow).URL("id", recipeID)
+		if err == nil {
+			return url.Path
+		}
+		logError(r, err)
+	}
+
+	url, err := cc.router.Get(RouteCollectionShow).URL("id", fmt.Sprintf("%v", collectionID))
+	if err != nil {
+		logError(r, err)
+		return "/collections"
+	}
+	return url.Path
+}
+
+func (cc *Collections) redirect(rw http.ResponseWriter, r *http.Request, route string, id uint) {
+	url, err := cc.router.Get(route).URL("id", fmt.Sprintf("%v", id))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/collections", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func (cc *Collections) getRecipe(rw http.ResponseWriter, r *http.Request, recipeID uint) (*models.Recipe, error) {
+	recipe, err := cc.rs.ByID(r.Context(), recipeID)
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Recipe not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find recipe", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if err := authorize(rw, r, cc.policy, policy.ActionView, recipe); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
+
+func (cc *Collections) getCollectionWithRecipes(rw http.ResponseWriter, r *http.Request) (*models.Collection, error) {
+	collection, err := cc.getCollection(rw, r)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes, err := cc.cs.Recipes(r.Context(), collection.ID)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Failed to fetch collection recipes", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	recipes, err = viewableRecipes(r, cc.policy, context.User(r.Context()), recipes)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Failed to fetch collection recipes", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	for i := range recipes {
+		images, err := cc.is.ByRecipeID(r.Context(), recipes[i].ID)
+		if err != nil {
+			http.Error(rw, "Failed to fetch recipe images", http.StatusInternalServerError)
+			return nil, err
+		}
+		recipes[i].Images = images
+	}
+
+	collection.Recipes = recipes
+	return collection, nil
+}
+
+func (cc *Collections) getCollection(rw http.ResponseWriter, r *http.Request) (*models.Collection, error) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	collectionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid collection ID", http.StatusNotFound)
+		return nil, err
+	}
+
+	collection, err := cc.cs.ByID(r.Context(), uint(collectionID))
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Collection not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find collection", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if err := authorize(rw, r, cc.policy, policy.ActionManage, collection); err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
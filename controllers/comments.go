@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+type RecipeComment struct {
+	models.Comment
+	Replies     []RecipeComment
+	CanEdit     bool
+	CanModerate bool
+}
+
+type CommentForm struct {
+	Body     string `schema:"body"`
+	ParentID uint   `schema:"parent_id"`
+}
+
+func (rc *Recipes) CommentCreate(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form CommentForm
+
+	recipe, err := rc.getRecipe(rw, r)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	comment := models.Comment{
+		RecipeID: recipe.ID,
+		UserID:   user.ID,
+		Body:     form.Body,
+	}
+	if form.ParentID != 0 {
+		comment.ParentID = &form.ParentID
+	}
+
+	err = rc.cms.Create(r.Context(), &comment)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	rc.redirectToShow(rw, r, recipe.ID)
+}
+
+func (rc *Recipes) CommentUpdate(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form CommentForm
+
+	comment, recipe, err := rc.getComment(rw, r)
+	if err != nil {
+		return
+	}
+
+	if err := authorize(rw, r, rc.policy, policy.ActionEdit, comment); err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	comment.Body = form.Body
+	err = rc.cms.Update(r.Context(), comment)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	rc.redirectToShow(rw, r, recipe.ID)
+}
+
+func (rc *Recipes) CommentDelete(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	comment, recipe, err := rc.getComment(rw, r)
+	if err != nil {
+		return
+	}
+
+	canEdit, canModerate, err := rc.commentPermissions(r, comment, recipe)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, views.AlertGenericMsg, http.StatusInternalServerError)
+		return
+	}
+	if !canEdit && !canModerate {
+		http.Error(rw, "Not found", http.StatusNotFound)
+		return
+	}
+
+	err = rc.cms.Delete(r.Context(), comment.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	rc.redirectToShow(rw, r, recipe.ID)
+}
+
+func (rc *Recipes) CommentHide(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	comment, recipe, err := rc.getComment(rw, r)
+	if err != nil {
+		return
+	}
+
+	comment.Hidden = !comment.Hidden
+	err = rc.cms.Update(r.Context(), comment)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	rc.redirectToShow(rw, r, recipe.ID)
+}
+
+func (rc *Recipes) recipeComments(r *http.Request, recipe *models.Recipe) ([]RecipeComment, error) {
+	comments, err := rc.cms.ByRecipeID(r.Context(), recipe.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user := context.User(r.Context())
+
+	canModerate, err := rc.policy.Can(r.Context(), user, policy.ActionManage, recipe)
+	if err != nil {
+		return nil, err
+	}
+
+	var build func([]models.Comment) []RecipeComment
+	build = func(level []models.Comment) []RecipeComment {
+		var thread []RecipeComment
+		for _, comment := range level {
+			canEdit := comment.UserID == user.ID
+			if comment.Hidden && !canEdit && !canModerate {
+				continue
+			}
+
+			thread = append(thread, RecipeComment{
+				Comment:     comment,
+				Replies:     build(comment.Replies),
+				CanEdit:     canEdit,
+				CanModerate: canModerate,
+			})
+		}
+		return thread
+	}
+
+	return build(models.Thread(comments)), nil
+}
+
+func (rc *Recipes) commentPermissions(r *http.Request, comment *models.Comment, recipe *models.Recipe) (bool, bool, error) {
+	user := context.User(r.Context())
+
+	canEdit, err := rc.policy.Can(r.Context(), user, policy.ActionEdit, comment)
+	if err != nil {
+		return false, false, err
+	}
+
+	canModerate, err := rc.policy.Can(r.Context(), user, policy.ActionManage, recipe)
+	if err != nil {
+		return false, false, err
+	}
+
+	return canEdit, canModerate, nil
+}
+
+func (rc *Recipes) getComment(rw http.ResponseWriter, r *http.Request) (*models.Comment, *models.Recipe, error) {
+	recipe, err := rc.getRecipe(rw, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commentID, err := strconv.Atoi(mux.Vars(r)["commentID"])
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid comment ID", http.StatusNotFound)
+		return nil, nil, err
+	}
+
+	comment, err := rc.cms.ByID(r.Context(), uint(commentID))
+	if err == nil && comment.RecipeID != recipe.ID {
+		err = models.ErrNotFound
+	}
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Comment not found", http.StatusNotFound)
+			return nil, nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find comment", http.StatusInternalServerError)
+		return nil, nil, err
+	}
+
+	return comment, recipe, nil
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/metrics"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+const (
+	RecipeSortRating     = "rating"
+	RecipeSortLastCooked = "last_cooked"
+)
+
+type CookLogForm struct {
+	CookedOn string `schema:"cooked_on"`
+	Rating   int    `schema:"rating"`
+	Notes    string `schema:"notes"`
+}
+
+func (rc *Recipes) CookLogCreate(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form CookLogForm
+
+	recipe, err := rc.getRecipe(rw, r)
+	if err != nil {
+		return
+	}
+
+	err = r.ParseMultipartForm(maxMultipartFormMemory)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	decoder.IgnoreUnknownKeys(true)
+	if err := decoder.Decode(&form, r.PostForm); err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	cookLog := models.CookLog{
+		UserID:   user.ID,
+		RecipeID: recipe.ID,
+		CookedOn: parseDate(form.CookedOn, time.Time{}),
+		Rating:   form.Rating,
+		Notes:    form.Notes,
+	}
+
+	var photo *multipart.FileHeader
+	if files := r.MultipartForm.File["photo"]; len(files) > 0 {
+		photo = files[0]
+		cookLog.Photo = filepath.Base(photo.Filename)
+	}
+
+	err = rc.cls.Create(r.Context(), &cookLog)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	if photo != nil {
+		if err := rc.savePhoto(r, &cookLog, photo); err != nil {
+			if err := rc.cls.Delete(r.Context(), cookLog.ID); err != nil {
+				logError(r, err)
+			}
+			vd.SetAlertDanger(err)
+			rc.renderShow(rw, r, recipe, vd)
+			return
+		}
+		metrics.AddImageUploadBytes(photo.Size)
+	}
+
+	rc.redirectToShow(rw, r, recipe.ID)
+}
+
+func (rc *Recipes) CookLogDelete(rw http.ResponseWriter, r *http.Request) {
+	recipe, err := rc.getRecipe(rw, r)
+	if err != nil {
+		return
+	}
+
+	cookLogID, err := strconv.Atoi(mux.Vars(r)["cookLogID"])
+	if err != nil {
+		http.Error(rw, "Invalid cook log ID", http.StatusNotFound)
+		return
+	}
+
+	cookLog, err := rc.cls.ByID(r.Context(), uint(cookLogID))
+	if err == nil && cookLog.RecipeID != recipe.ID {
+		err = models.ErrNotFound
+	}
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Cook log not found", http.StatusNotFound)
+			return
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find cook log", http.StatusInternalServerError)
+		return
+	}
+
+	canDelete, err := rc.cookLogPermissions(r, cookLog, recipe)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, views.AlertGenericMsg, http.StatusInternalServerError)
+		return
+	}
+	if !canDelete {
+		http.Error(rw, "Not found", http.StatusNotFound)
+		return
+	}
+
+	err = rc.cls.Delete(r.Context(), cookLog.ID)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, views.AlertGenericMsg, http.StatusInternalServerError)
+		return
+	}
+
+	if cookLog.Photo != "" {
+		if err := rc.is.DeletePhotoDir(r.Context(), cookLog.ID); err != nil {
+			logError(r, err)
+		}
+	}
+
+	rc.redirectToShow(rw, r, recipe.ID)
+}
+
+func (rc *Recipes) savePhoto(r *http.Request, cookLog *models.CookLog, photo *multipart.FileHeader) error {
+	src, err := photo.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return rc.is.CreatePhoto(r.Context(), cookLog.PhotoImage(), src)
+}
+
+func (rc *Recipes) cookLogPermissions(r *http.Request, cookLog *models.CookLog, recipe *models.Recipe) (bool, error) {
+	user := context.User(r.Context())
+
+	isAuthor, err := rc.policy.Can(r.Context(), user, policy.ActionManage, cookLog)
+	if err != nil || isAuthor {
+		return isAuthor, err
+	}
+
+	return rc.policy.Can(r.Context(), user, policy.ActionManage, recipe)
+}
+
+func (rc *Recipes) redirectToShow(rw http.ResponseWriter, r *http.Request, id uint) {
+	url, err := rc.router.Get(RouteRecipeShow).URL("id", fmt.Sprintf("%v", id))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/recipes", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
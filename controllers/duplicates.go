@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+func (rc *Recipes) Duplicates(rw http.ResponseWriter, r *http.Request) {
+	rc.renderDuplicates(rw, r, views.Data{})
+}
+
+type RecipeMergeForm struct {
+	CanonicalID uint   `schema:"canonical_id"`
+	RecipeIDs   []uint `schema:"recipe_ids"`
+}
+
+func (rc *Recipes) Merge(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form RecipeMergeForm
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderDuplicates(rw, r, vd)
+		return
+	}
+
+	canonical, err := rc.mergeRecipe(rw, r, form.CanonicalID)
+	if err != nil {
+		return
+	}
+
+	var duplicates []*models.Recipe
+	var duplicateIDs []uint
+	for _, id := range form.RecipeIDs {
+		if id == canonical.ID {
+			continue
+		}
+
+		duplicate, err := rc.mergeRecipe(rw, r, id)
+		if err != nil {
+			return
+		}
+		duplicates = append(duplicates, duplicate)
+		duplicateIDs = append(duplicateIDs, duplicate.ID)
+	}
+
+	var copies []models.Image
+	for _, duplicate := range duplicates {
+		images, err := rc.is.ByRecipeID(r.Context(), duplicate.ID)
+		if err != nil {
+			rc.discardCopies(r, copies)
+			vd.SetAlertDanger(err)
+			rc.renderDuplicates(rw, r, vd)
+			return
+		}
+		duplicate.Images = images
+
+		for i := range images {
+			image, err := rc.is.Copy(r.Context(), &images[i], canonical.ID)
+			if err != nil {
+				rc.discardCopies(r, copies)
+				vd.SetAlertDanger(err)
+				rc.renderDuplicates(rw, r, vd)
+				return
+			}
+			copies = append(copies, *image)
+		}
+	}
+
+	err = rc.rs.Merge(r.Context(), canonical.ID, duplicateIDs)
+	if err != nil {
+		rc.discardCopies(r, copies)
+		vd.SetAlertDanger(err)
+		rc.renderDuplicates(rw, r, vd)
+		return
+	}
+
+	for _, duplicate := range duplicates {
+		for i := range duplicate.Images {
+			if err := rc.is.Delete(r.Context(), &duplicate.Images[i]); err != nil {
+				logError(r, err)
+			}
+		}
+	}
+
+	rc.redirectToShow(rw, r, canonical.ID)
+}
+
+func (rc *Recipes) discardCopies(r *http.Request, copies []models.Image) {
+	for i := range copies {
+		if err := rc.is.Delete(r.Context(), &copies[i]); err != nil {
+			logError(r, err)
+		}
+	}
+}
+
+func (rc *Recipes) warnDuplicates(r *http.Request, recipe *models.Recipe, vd *views.Data) bool {
+	duplicates, err := rc.rs.Duplicates(r.Context(), recipe)
+	if err != nil {
+		logError(r, err)
+		return false
+	}
+	if len(duplicates) == 0 {
+		return false
+	}
+
+	msg := fmt.Sprintf("This recipe looks like a duplicate of \"%s\".", duplicates[0].Title)
+	if len(duplicates) > 1 {
+		msg = fmt.Sprintf("This recipe looks like a duplicate of \"%s\" and %d other recipes.", duplicates[0].Title, len(duplicates)-1)
+	}
+	vd.SetWarning(msg, "/recipes/duplicates", "Review duplicates")
+	return true
+}
+
+func (rc *Recipes) renderDuplicates(rw http.ResponseWriter, r *http.Request, vd views.Data) {
+	user := context.User(r.Context())
+
+	groups, err := rc.rs.DuplicateGroups(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+
+	vd.Yield = groups
+	rc.DuplicatesView.Render(rw, r, vd)
+}
+
+func (rc *Recipes) mergeRecipe(rw http.ResponseWriter, r *http.Request, id uint) (*models.Recipe, error) {
+	recipe, err := rc.rs.ByID(r.Context(), id)
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Recipe not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find recipe", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if err := authorize(rw, r, rc.policy, policy.ActionManage, recipe); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
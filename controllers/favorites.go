@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+type Favorites struct {
+	IndexView *views.View
+	fs        models.FavoriteService
+	policy    *policy.Policy
+	router    *mux.Router
+}
+
+func NewFavorites(vs *views.Registry, fs models.FavoriteService, p *policy.Policy, router *mux.Router) *Favorites {
+	return &Favorites{
+		IndexView: vs.NewView("favorites/index", sampleRecipes()),
+		fs:        fs,
+		policy:    p,
+		router:    router,
+	}
+}
+
+func (fc *Favorites) Index(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	user := context.User(r.Context())
+
+	recipes, err := fc.fs.Recipes(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		fc.IndexView.Render(rw, r, vd)
+		return
+	}
+
+	vd.Yield, err = viewableRecipes(r, fc.policy, user, recipes)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+	fc.IndexView.Render(rw, r, vd)
+}
+
+func (fc *Favorites) Toggle(rw http.ResponseWriter, r *http.Request) {
+	recipe := context.Recipe(r.Context())
+	if recipe == nil {
+		http.Error(rw, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	favorite, err := fc.fs.IsFavorite(r.Context(), user.ID, recipe.ID)
+	if err == nil {
+		if favorite {
+			err = fc.fs.Remove(r.Context(), user.ID, recipe.ID)
+		} else {
+			err = fc.fs.Add(r.Context(), user.ID, recipe.ID)
+		}
+	}
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, views.AlertGenericMsg, http.StatusInternalServerError)
+		return
+	}
+
+	url, err := fc.router.Get(RouteRecipeShow).URL("id", fmt.Sprintf("%v", recipe.ID))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/favorites", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func viewableRecipes(r *http.Request, p *policy.Policy, user *models.User, recipes []models.Recipe) ([]models.Recipe, error) {
+	viewable := make([]models.Recipe, 0, len(recipes))
+	for i := range recipes {
+		allowed, err := p.Can(r.Context(), user, policy.ActionView, &recipes[i])
+		if err != nil {
+			return viewable, err
+		}
+		if allowed {
+			viewable = append(viewable, recipes[i])
+		}
+	}
+	return viewable, nil
+}
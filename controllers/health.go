@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+type Pinger interface {
+	Ping(context.Context) error
+}
+
+type Health struct {
+	db Pinger
+	is models.ImageService
+}
+
+func NewHealth(db Pinger, is models.ImageService) *Health {
+	return &Health{
+		db: db,
+		is: is,
+	}
+}
+
+func (h *Health) Healthz(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(rw, "ok")
+}
+
+func (h *Health) Readyz(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	checks := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{"database", h.db.Ping},
+		{"image storage", h.is.CheckWritable},
+	}
+
+	for _, c := range checks {
+		if err := c.check(r.Context()); err != nil {
+			logError(r, err)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(rw, "%s unavailable\n", c.name)
+			return
+		}
+	}
+
+	fmt.Fprintln(rw, "ok")
+}
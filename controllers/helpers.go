@@ -4,6 +4,9 @@ import (
 	"net/http"
 
 	"github.com/gorilla/schema"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
 )
 
 var decoder = schema.NewDecoder()
@@ -22,3 +25,31 @@ func parseForm(r *http.Request, dst interface{}) error {
 
 	return nil
 }
+
+func authorize(rw http.ResponseWriter, r *http.Request, p *policy.Policy, action policy.Action, resource interface{}) error {
+	allowed, err := p.Can(r.Context(), context.User(r.Context()), action, resource)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to authorize request", http.StatusInternalServerError)
+		return err
+	}
+
+	if !allowed {
+		http.Error(rw, "Not found", http.StatusNotFound)
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func logError(r *http.Request, err error) {
+	context.Logger(r.Context()).Error("request failed", "error", err)
+}
+
+func absoluteURL(r *http.Request, path string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + path
+}
@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+const monthLayout = "2006-01"
+
+type MealPlans struct {
+	WeekView  *views.View
+	MonthView *views.View
+	ms        models.MealPlanService
+	rs        models.RecipeService
+	policy    *policy.Policy
+}
+
+type MealPlanWeek struct {
+	Start    time.Time
+	Previous time.Time
+	Next     time.Time
+	Slots    []models.MealSlot
+	Days     []MealPlanDay
+	Recipes  []models.Recipe
+}
+
+type MealPlanMonth struct {
+	Month    time.Time
+	Previous time.Time
+	Next     time.Time
+	Weeks    [][]MealPlanDay
+}
+
+type MealPlanDay struct {
+	Date    time.Time
+	InMonth bool
+	Slots   []MealPlanSlot
+}
+
+type MealPlanSlot struct {
+	Slot    models.MealSlot
+	Entries []models.MealPlanEntry
+}
+
+func NewMealPlans(vs *views.Registry, ms models.MealPlanService, rs models.RecipeService, p *policy.Policy) *MealPlans {
+	return &MealPlans{
+		WeekView:  vs.NewView("mealplans/week", sampleMealPlanWeek()),
+		MonthView: vs.NewView("mealplans/month", sampleMealPlanMonth()),
+		ms:        ms,
+		rs:        rs,
+		policy:    p,
+	}
+}
+
+func (mc *MealPlans) Week(rw http.ResponseWriter, r *http.Request) {
+	start := models.StartOfWeek(parseDate(r.URL.Query().Get("week"), time.Now()))
+	mc.renderWeek(rw, r, start, views.Data{})
+}
+
+func (mc *MealPlans) Month(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	month, err := time.Parse(monthLayout, r.URL.Query().Get("month"))
+	if err != nil {
+		now := time.Now()
+		month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	data := MealPlanMonth{
+		Month:    month,
+		Previous: month.AddDate(0, -1, 0),
+		Next:     month.AddDate(0, 1, 0),
+	}
+	vd.Yield = &data
+
+	gridStart := models.StartOfWeek(month)
+	gridEnd := models.StartOfWeek(data.Next.AddDate(0, 0, 6))
+
+	user := context.User(r.Context())
+	entries, err := mc.ms.ByUserIDBetween(r.Context(), user.ID, gridStart, gridEnd)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		mc.MonthView.Render(rw, r, vd)
+		return
+	}
+
+	days := buildMealPlanDays(gridStart, gridEnd, entries)
+	for i := 0; i < len(days); i += 7 {
+		week := days[i : i+7]
+		for j := range week {
+			week[j].InMonth = week[j].Date.Month() == month.Month()
+		}
+		data.Weeks = append(data.Weeks, week)
+	}
+
+	mc.MonthView.Render(rw, r, vd)
+}
+
+type MealPlanEntryForm struct {
+	RecipeID uint   `schema:"recipe_id"`
+	Date     string `schema:"date"`
+	Slot     string `schema:"slot"`
+	Servings int    `schema:"servings"`
+}
+
+func (mc *MealPlans) Create(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form MealPlanEntryForm
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, models.StartOfWeek(time.Now()), vd)
+		return
+	}
+
+	date := parseDate(form.Date, time.Time{})
+
+	recipe, err := mc.rs.ByID(r.Context(), form.RecipeID)
+	if err != nil {
+		if err == models.ErrNotFound {
+			err = models.ErrMealPlanRecipeRequired
+		}
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, models.StartOfWeek(date), vd)
+		return
+	}
+
+	if err := authorize(rw, r, mc.policy, policy.ActionView, recipe); err != nil {
+		return
+	}
+
+	user := context.User(r.Context())
+
+	entry := models.MealPlanEntry{
+		UserID:   user.ID,
+		RecipeID: recipe.ID,
+		Date:     date,
+		Slot:     models.MealSlot(form.Slot),
+		Servings: form.Servings,
+	}
+
+	err = mc.ms.Create(r.Context(), &entry)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, models.StartOfWeek(date), vd)
+		return
+	}
+
+	redirectToWeek(rw, r, entry.Date)
+}
+
+func (mc *MealPlans) Update(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form MealPlanEntryForm
+
+	entry, err := mc.getEntry(rw, r)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, models.StartOfWeek(entry.Date), vd)
+		return
+	}
+
+	if form.Date != "" {
+		entry.Date = parseDate(form.Date, time.Time{})
+	}
+	if form.Slot != "" {
+		entry.Slot = models.MealSlot(form.Slot)
+	}
+	if _, ok := r.PostForm["servings"]; ok {
+		entry.Servings = form.Servings
+	}
+
+	err = mc.ms.Update(r.Context(), entry)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, models.StartOfWeek(entry.Date), vd)
+		return
+	}
+
+	redirectToWeek(rw, r, entry.Date)
+}
+
+func (mc *MealPlans) Delete(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	entry, err := mc.getEntry(rw, r)
+	if err != nil {
+		return
+	}
+
+	err = mc.ms.Delete(r.Context(), entry.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, models.StartOfWeek(entry.Date), vd)
+		return
+	}
+
+	redirectToWeek(rw, r, entry.Date)
+}
+
+type MealPlanRepeatForm struct {
+	Week string `schema:"week"`
+}
+
+func (mc *MealPlans) Repeat(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form MealPlanRepeatForm
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, models.StartOfWeek(time.Now()), vd)
+		return
+	}
+
+	from := models.StartOfWeek(parseDate(form.Week, time.Now()))
+	to := from.AddDate(0, 0, 7)
+
+	user := context.User(r.Context())
+
+	err := mc.ms.RepeatWeek(r.Context(), user.ID, from, to)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		mc.renderWeek(rw, r, from, vd)
+		return
+	}
+
+	redirectToWeek(rw, r, to)
+}
+
+func (mc *MealPlans) renderWeek(rw http.ResponseWriter, r *http.Request, start time.Time, vd views.Data) {
+	data := MealPlanWeek{
+		Start:    start,
+		Previous: start.AddDate(0, 0, -7),
+		Next:     start.AddDate(0, 0, 7),
+		Slots:    models.MealSlots,
+	}
+	vd.Yield = &data
+
+	user := context.User(r.Context())
+
+	entries, err := mc.ms.ByUserIDBetween(r.Context(), user.ID, start, data.Next)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		mc.WeekView.Render(rw, r, vd)
+		return
+	}
+	data.Days = buildMealPlanDays(start, data.Next, entries)
+
+	data.Recipes, err = mc.rs.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+
+	mc.WeekView.Render(rw, r, vd)
+}
+
+func (mc *MealPlans) getEntry(rw http.ResponseWriter, r *http.Request) (*models.MealPlanEntry, error) {
+	entryID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid meal plan entry ID", http.StatusNotFound)
+		return nil, err
+	}
+
+	entry, err := mc.ms.ByID(r.Context(), uint(entryID))
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Meal plan entry not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find meal plan entry", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if err := authorize(rw, r, mc.policy, policy.ActionManage, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func buildMealPlanDays(from, to time.Time, entries []models.MealPlanEntry) []MealPlanDay {
+	var days []MealPlanDay
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		day := MealPlanDay{Date: date}
+		for _, slot := range models.MealSlots {
+			mealSlot := MealPlanSlot{Slot: slot}
+			for _, entry := range entries {
+				if entry.Slot == slot && models.StartOfDay(entry.Date).Equal(date) {
+					mealSlot.Entries = append(mealSlot.Entries, entry)
+				}
+			}
+			day.Slots = append(day.Slots, mealSlot)
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func redirectToWeek(rw http.ResponseWriter, r *http.Request, date time.Time) {
+	week := models.StartOfWeek(date).Format(models.DateLayout)
+	http.Redirect(rw, r, fmt.Sprintf("/mealplan?week=%s", week), http.StatusFound)
+}
+
+func parseDate(value string, fallback time.Time) time.Time {
+	date, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return fallback
+	}
+	return date
+}
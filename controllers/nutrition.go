@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/nutrition"
+	"github.com/mpanelo/gocookit/views"
+)
+
+type Nutrition struct {
+	EditView *views.View
+	ns       models.NutritionService
+	router   *mux.Router
+}
+
+type NutritionEditData struct {
+	*models.Recipe
+	Label *nutrition.Label
+	Foods []nutrition.Food
+}
+
+func NewNutrition(vs *views.Registry, ns models.NutritionService, router *mux.Router) *Nutrition {
+	return &Nutrition{
+		EditView: vs.NewView("nutrition/edit", sampleNutritionEdit()),
+		ns:       ns,
+		router:   router,
+	}
+}
+
+func (nc *Nutrition) Edit(rw http.ResponseWriter, r *http.Request) {
+	nc.renderEdit(rw, r, views.Data{})
+}
+
+type NutritionForm struct {
+	Matches []NutritionMatchForm `schema:"matches"`
+}
+
+type NutritionMatchForm struct {
+	Ingredient string `schema:"ingredient"`
+	FoodID     string `schema:"food_id"`
+}
+
+func (nc *Nutrition) Update(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form NutritionForm
+
+	recipe := context.Recipe(r.Context())
+	if recipe == nil {
+		http.Error(rw, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		nc.renderEdit(rw, r, vd)
+		return
+	}
+
+	for _, m := range form.Matches {
+		var err error
+		if m.FoodID == "" {
+			err = nc.ns.Delete(r.Context(), recipe.ID, m.Ingredient)
+		} else {
+			err = nc.ns.Save(r.Context(), &models.NutritionMatch{
+				RecipeID:   recipe.ID,
+				Ingredient: m.Ingredient,
+				FoodID:     m.FoodID,
+			})
+		}
+		if err != nil {
+			vd.SetAlertDanger(err)
+			nc.renderEdit(rw, r, vd)
+			return
+		}
+	}
+
+	url, err := nc.router.Get(RouteRecipeShow).URL("id", fmt.Sprintf("%v", recipe.ID))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/recipes", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func (nc *Nutrition) renderEdit(rw http.ResponseWriter, r *http.Request, vd views.Data) {
+	recipe := context.Recipe(r.Context())
+	if recipe == nil {
+		http.Error(rw, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	data := NutritionEditData{Recipe: recipe, Foods: nutrition.Foods()}
+	vd.Yield = &data
+
+	label, err := nc.ns.Label(r.Context(), recipe)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+	data.Label = label
+
+	nc.EditView.Render(rw, r, vd)
+}
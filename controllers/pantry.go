@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+type Pantry struct {
+	IndexView    *views.View
+	CookableView *views.View
+	ps           models.PantryService
+	rs           models.RecipeService
+	ss           models.ShoppingListService
+	policy       *policy.Policy
+	router       *mux.Router
+}
+
+type CookableData struct {
+	Matches       []models.RecipeMatch
+	ShoppingLists []models.ShoppingList
+}
+
+func NewPantry(vs *views.Registry, ps models.PantryService, rs models.RecipeService, ss models.ShoppingListService, p *policy.Policy, router *mux.Router) *Pantry {
+	return &Pantry{
+		IndexView:    vs.NewView("pantry/index", samplePantryItems()),
+		CookableView: vs.NewView("pantry/cookable", sampleCookable()),
+		ps:           ps,
+		rs:           rs,
+		ss:           ss,
+		policy:       p,
+		router:       router,
+	}
+}
+
+func (pc *Pantry) Index(rw http.ResponseWriter, r *http.Request) {
+	pc.renderIndex(rw, r, views.Data{})
+}
+
+type PantryItemForm struct {
+	Name      string  `schema:"name"`
+	Quantity  float64 `schema:"quantity"`
+	Unit      string  `schema:"unit"`
+	ExpiresAt string  `schema:"expires_at"`
+}
+
+func (pc *Pantry) Create(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form PantryItemForm
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		pc.renderIndex(rw, r, vd)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	item := models.PantryItem{UserID: user.ID}
+	form.apply(&item)
+
+	err := pc.ps.Create(r.Context(), &item)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		pc.renderIndex(rw, r, vd)
+		return
+	}
+
+	http.Redirect(rw, r, "/pantry", http.StatusFound)
+}
+
+func (pc *Pantry) Update(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form PantryItemForm
+
+	item, err := pc.getPantryItem(rw, r)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		pc.renderIndex(rw, r, vd)
+		return
+	}
+
+	form.apply(item)
+
+	err = pc.ps.Update(r.Context(), item)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		pc.renderIndex(rw, r, vd)
+		return
+	}
+
+	http.Redirect(rw, r, "/pantry", http.StatusFound)
+}
+
+func (pc *Pantry) Delete(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	item, err := pc.getPantryItem(rw, r)
+	if err != nil {
+		return
+	}
+
+	err = pc.ps.Delete(r.Context(), item.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		pc.renderIndex(rw, r, vd)
+		return
+	}
+
+	http.Redirect(rw, r, "/pantry", http.StatusFound)
+}
+
+func (pc *Pantry) Cookable(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var data CookableData
+
+	user := context.User(r.Context())
+
+	matches, err := pc.matches(r, user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		pc.CookableView.Render(rw, r, vd)
+		return
+	}
+	data.Matches = matches
+
+	data.ShoppingLists, err = pc.ss.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+
+	vd.Yield = &data
+	pc.CookableView.Render(rw, r, vd)
+}
+
+type AddMissingForm struct {
+	ShoppingListID uint `schema:"shopping_list_id"`
+}
+
+func (pc *Pantry) AddMissing(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form AddMissingForm
+
+	recipe := context.Recipe(r.Context())
+	if recipe == nil {
+		http.Error(rw, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		pc.CookableView.Render(rw, r, vd)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	items, err := pc.ps.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		pc.CookableView.Render(rw, r, vd)
+		return
+	}
+	match := models.MatchRecipes([]models.Recipe{*recipe}, items)
+
+	list := &models.ShoppingList{
+		UserID: user.ID,
+		Title:  fmt.Sprintf("Missing for %s", recipe.Title),
+	}
+	if form.ShoppingListID != 0 {
+		list, err = pc.ss.ByID(r.Context(), form.ShoppingListID)
+		if err != nil {
+			vd.SetAlertDanger(err)
+			pc.CookableView.Render(rw, r, vd)
+			return
+		}
+
+		if err := authorize(rw, r, pc.policy, policy.ActionManage, list); err != nil {
+			return
+		}
+	}
+
+	if len(match) > 0 {
+		list.AddIngredients(match[0].Missing)
+	}
+
+	if list.ID == 0 {
+		err = pc.ss.Create(r.Context(), list)
+	} else {
+		err = pc.ss.Update(r.Context(), list)
+	}
+	if err != nil {
+		vd.SetAlertDanger(err)
+		pc.CookableView.Render(rw, r, vd)
+		return
+	}
+
+	url, err := pc.router.Get(RouteShoppingListShow).URL("id", fmt.Sprintf("%v", list.ID))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/shoppinglists", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func (pc *Pantry) matches(r *http.Request, userID uint) ([]models.RecipeMatch, error) {
+	recipes, err := pc.rs.ByUserID(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := pc.ps.ByUserID(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.MatchRecipes(recipes, items), nil
+}
+
+func (pc *Pantry) renderIndex(rw http.ResponseWriter, r *http.Request, vd views.Data) {
+	user := context.User(r.Context())
+
+	items, err := pc.ps.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+
+	vd.Yield = items
+	pc.IndexView.Render(rw, r, vd)
+}
+
+func (pc *Pantry) getPantryItem(rw http.ResponseWriter, r *http.Request) (*models.PantryItem, error) {
+	itemID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid pantry item ID", http.StatusNotFound)
+		return nil, err
+	}
+
+	item, err := pc.ps.ByID(r.Context(), uint(itemID))
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Pantry item not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find pantry item", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if err := authorize(rw, r, pc.policy, policy.ActionManage, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (f *PantryItemForm) apply(item *models.PantryItem) {
+	item.Name = f.Name
+	item.Quantity = f.Quantity
+	item.Unit = f.Unit
+	item.ExpiresAt = nil
+
+	if expiresAt, err := time.Parse(models.DateLayout, f.ExpiresAt); err == nil {
+		item.ExpiresAt = &expiresAt
+	}
+}
@@ -2,13 +2,18 @@ package controllers
 
 import (
 	"fmt"
-	"log"
 	"net/http"
-	"strconv"
+	"sort"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/dietary"
+	"github.com/mpanelo/gocookit/markdown"
+	"github.com/mpanelo/gocookit/metrics"
 	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/nutrition"
+	"github.com/mpanelo/gocookit/policy"
 	"github.com/mpanelo/gocookit/views"
 )
 
@@ -20,24 +25,77 @@ const (
 )
 
 type Recipes struct {
-	NewView   *views.View
-	EditView  *views.View
-	IndexView *views.View
-	ShowView  *views.View
-	rs        models.RecipeService
-	is        models.ImageService
-	router    *mux.Router
+	NewView        *views.View
+	EditView       *views.View
+	IndexView      *views.View
+	ShowView       *views.View
+	DuplicatesView *views.View
+	rs             models.RecipeService
+	is             models.ImageService
+	cs             models.CollectionService
+	ws             models.WorkspaceService
+	ns             models.NutritionService
+	cls            models.CookLogService
+	cms            models.CommentService
+	fs             models.FavoriteService
+	rcs            models.RecommendationService
+	policy         *policy.Policy
+	router         *mux.Router
 }
 
-func NewRecipes(rs models.RecipeService, is models.ImageService, router *mux.Router) *Recipes {
+type RecipeShowData struct {
+	*models.Recipe
+	Collections []RecipeCollection
+	Workspaces  []models.Workspace
+	Nutrition   *nutrition.Label
+	CookStats   models.CookStats
+	CookLogs    []models.CookLog
+	Comments    []RecipeComment
+	Favorite    bool
+	Related     []models.Recipe
+	UserID      uint
+	Today       string
+	CanEdit     bool
+	CanManage   bool
+}
+
+type RecipeIndexData struct {
+	Recipes   []RecipeCard
+	Filter    models.RecipeFilter
+	Sort      string
+	Recent    []models.Recipe
+	Allergens []dietary.Allergen
+	Diets     []dietary.Diet
+}
+
+type RecipeCard struct {
+	models.Recipe
+	CookStats models.CookStats
+}
+
+type RecipeCollection struct {
+	models.Collection
+	Contains bool
+}
+
+func NewRecipes(vs *views.Registry, rs models.RecipeService, is models.ImageService, cs models.CollectionService, ws models.WorkspaceService, ns models.NutritionService, cls models.CookLogService, cms models.CommentService, fs models.FavoriteService, rcs models.RecommendationService, p *policy.Policy, router *mux.Router) *Recipes {
 	return &Recipes{
-		NewView:   views.NewView("recipes/new"),
-		EditView:  views.NewView("recipes/edit"),
-		IndexView: views.NewView("recipes/index"),
-		ShowView:  views.NewView("recipes/show"),
-		rs:        rs,
-		is:        is,
-		router:    router,
+		NewView:        vs.NewView("recipes/new", nil),
+		EditView:       vs.NewView("recipes/edit", sampleRecipe()),
+		IndexView:      vs.NewView("recipes/index", sampleRecipeIndex()),
+		ShowView:       vs.NewView("recipes/show", sampleRecipeShow()),
+		DuplicatesView: vs.NewView("recipes/duplicates", sampleDuplicates()),
+		rs:             rs,
+		is:             is,
+		cs:             cs,
+		ws:             ws,
+		ns:             ns,
+		cls:            cls,
+		cms:            cms,
+		fs:             fs,
+		rcs:            rcs,
+		policy:         p,
+		router:         router,
 	}
 }
 
@@ -49,12 +107,6 @@ func (rc *Recipes) ImageUpload(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user := context.User(r.Context())
-	if recipe.UserID != user.ID {
-		http.Error(rw, "Recipe not found", http.StatusNotFound)
-		return
-	}
-
 	vd.Yield = recipe
 
 	err = r.ParseMultipartForm(maxMultipartFormMemory)
@@ -73,12 +125,13 @@ func (rc *Recipes) ImageUpload(rw http.ResponseWriter, r *http.Request) {
 		}
 		defer srcFile.Close()
 
-		err = rc.is.Create(recipe.ID, srcFile, imageFile.Filename)
+		err = rc.is.Create(r.Context(), recipe.ID, srcFile, imageFile.Filename)
 		if err != nil {
 			vd.SetAlertDanger(err)
 			rc.EditView.Render(rw, r, vd)
 			return
 		}
+		metrics.AddImageUploadBytes(imageFile.Size)
 	}
 
 	vd.SetSuccess("Images uploaded successfully")
@@ -95,13 +148,7 @@ func (rc *Recipes) ImageDelete(rw http.ResponseWriter, r *http.Request) {
 
 	vd.Yield = recipe
 
-	user := context.User(r.Context())
-	if recipe.UserID != user.ID {
-		http.Error(rw, "Recipe not found", http.StatusNotFound)
-		return
-	}
-
-	err = rc.is.Delete(&models.Image{
+	err = rc.is.Delete(r.Context(), &models.Image{
 		RecipeID: recipe.ID,
 		Filename: mux.Vars(r)["filename"],
 	})
@@ -114,7 +161,7 @@ func (rc *Recipes) ImageDelete(rw http.ResponseWriter, r *http.Request) {
 
 	url, err := rc.router.Get(RouteRecipeEdit).URL("id", fmt.Sprintf("%v", recipe.ID))
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		http.Redirect(rw, r, "/recipes", http.StatusFound)
 		return
 	}
@@ -130,31 +177,334 @@ func (rc *Recipes) Show(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	user := context.User(r.Context())
-	if recipe.UserID != user.ID {
-		http.Error(rw, "Recipe not found", http.StatusNotFound)
+	if err := rc.rcs.RecordCoView(r.Context(), user.ID, recipe.ID); err != nil {
+		logError(r, err)
+	}
+	if err := rc.fs.RecordView(r.Context(), user.ID, recipe.ID); err != nil {
+		logError(r, err)
+	}
+
+	rc.renderShow(rw, r, recipe, vd)
+}
+
+func (rc *Recipes) renderShow(rw http.ResponseWriter, r *http.Request, recipe *models.Recipe, vd views.Data) {
+	data := RecipeShowData{Recipe: recipe}
+	vd.Yield = &data
+
+	err := rc.setForks(r, recipe)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
 		return
 	}
 
-	vd.Yield = recipe
+	user := context.User(r.Context())
+	data.Collections, err = rc.recipeCollections(r, user.ID, recipe.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	data.Nutrition, err = rc.ns.Label(r.Context(), recipe)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	stats, err := rc.cls.Stats(r.Context(), recipe.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+	data.CookStats = stats[recipe.ID]
+
+	data.CookLogs, err = rc.cls.RecentByRecipeID(r.Context(), recipe.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+	data.Comments, err = rc.recipeComments(r, recipe)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	data.Favorite, err = rc.fs.IsFavorite(r.Context(), user.ID, recipe.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	data.Related, err = rc.relatedRecipes(r, user, recipe.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	data.UserID = user.ID
+	data.Today = time.Now().Format(models.DateLayout)
+
+	data.CanEdit, err = rc.policy.Can(r.Context(), user, policy.ActionEdit, recipe)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	data.CanManage, err = rc.policy.Can(r.Context(), user, policy.ActionManage, recipe)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	if data.CanManage {
+		data.Workspaces, err = rc.ws.ByUserID(r.Context(), user.ID)
+		if err != nil {
+			vd.SetAlertDanger(err)
+		}
+	}
+
 	rc.ShowView.Render(rw, r, vd)
 }
 
+func (rc *Recipes) relatedRecipes(r *http.Request, user *models.User, recipeID uint) ([]models.Recipe, error) {
+	related, err := rc.rcs.Related(r.Context(), recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err = viewableRecipes(r, rc.policy, user, related)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(related) > models.RelatedRecipesLimit {
+		related = related[:models.RelatedRecipesLimit]
+	}
+	return related, nil
+}
+
+type RecipeWorkspaceForm struct {
+	WorkspaceID uint `schema:"workspace_id"`
+}
+
+func (rc *Recipes) Share(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form RecipeWorkspaceForm
+
+	recipe, err := rc.getRecipe(rw, r)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	if form.WorkspaceID == 0 {
+		recipe.WorkspaceID = nil
+	} else {
+		workspace, err := rc.ws.ByID(r.Context(), form.WorkspaceID)
+		if err != nil {
+			vd.SetAlertDanger(err)
+			rc.renderShow(rw, r, recipe, vd)
+			return
+		}
+
+		if err := authorize(rw, r, rc.policy, policy.ActionEdit, workspace); err != nil {
+			return
+		}
+		recipe.WorkspaceID = &workspace.ID
+	}
+
+	err = rc.rs.Update(r.Context(), recipe)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	url, err := rc.router.Get(RouteRecipeShow).URL("id", fmt.Sprintf("%v", recipe.ID))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/recipes", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func (rc *Recipes) recipeCollections(r *http.Request, userID, recipeID uint) ([]RecipeCollection, error) {
+	collections, err := rc.cs.ByUserID(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	containing, err := rc.cs.ByRecipeID(r.Context(), recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	contains := make(map[uint]bool, len(containing))
+	for _, c := range containing {
+		contains[c.ID] = true
+	}
+
+	recipeCollections := make([]RecipeCollection, len(collections))
+	for i, c := range collections {
+		recipeCollections[i] = RecipeCollection{
+			Collection: c,
+			Contains:   contains[c.ID],
+		}
+	}
+	return recipeCollections, nil
+}
+
+func (rc *Recipes) Fork(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	recipe, err := rc.getRecipe(rw, r)
+	if err != nil {
+		return
+	}
+
+	user := context.User(r.Context())
+
+	fork := recipe.Fork(user.ID)
+	err = rc.rs.Create(r.Context(), fork)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.renderShow(rw, r, recipe, vd)
+		return
+	}
+
+	for i := range recipe.Images {
+		_, err = rc.is.Copy(r.Context(), &recipe.Images[i], fork.ID)
+		if err != nil {
+			rc.discardFork(r, fork)
+			vd.SetAlertDanger(err)
+			rc.renderShow(rw, r, recipe, vd)
+			return
+		}
+	}
+
+	url, err := rc.router.Get(RouteRecipeEdit).URL("id", fmt.Sprintf("%v", fork.ID))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/recipes", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func (rc *Recipes) discardFork(r *http.Request, fork *models.Recipe) {
+	if err := rc.is.DeleteRecipeDir(r.Context(), fork.ID); err != nil {
+		logError(r, err)
+	}
+	if err := rc.rs.Delete(r.Context(), fork.ID); err != nil {
+		logError(r, err)
+	}
+}
+
 func (rc *Recipes) Index(rw http.ResponseWriter, r *http.Request) {
 	var vd views.Data
 
 	user := context.User(r.Context())
 
-	recipes, err := rc.rs.ByUserID(user.ID)
+	data := RecipeIndexData{
+		Filter:    parseRecipeFilter(r),
+		Sort:      r.URL.Query().Get("sort"),
+		Allergens: dietary.Allergens,
+		Diets:     dietary.Diets,
+	}
+	vd.Yield = &data
+
+	recipes, err := rc.rs.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.IndexView.Render(rw, r, vd)
+		return
+	}
+
+	recipes = data.Filter.Apply(recipes)
+
+	ids := make([]uint, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+
+	stats, err := rc.cls.Stats(r.Context(), ids...)
 	if err != nil {
 		vd.SetAlertDanger(err)
 		rc.IndexView.Render(rw, r, vd)
 		return
 	}
 
-	vd.Yield = recipes
+	data.Recipes = make([]RecipeCard, len(recipes))
+	for i, recipe := range recipes {
+		data.Recipes[i] = RecipeCard{Recipe: recipe, CookStats: stats[recipe.ID]}
+	}
+	sortRecipeCards(data.Recipes, data.Sort)
+
+	recent, err := rc.fs.RecentlyViewed(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		rc.IndexView.Render(rw, r, vd)
+		return
+	}
+
+	data.Recent, err = viewableRecipes(r, rc.policy, user, recent)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+
 	rc.IndexView.Render(rw, r, vd)
 }
 
+func sortRecipeCards(cards []RecipeCard, by string) {
+	switch by {
+	case RecipeSortRating:
+		sort.SliceStable(cards, func(i, j int) bool {
+			return cards[i].CookStats.AverageRating > cards[j].CookStats.AverageRating
+		})
+	case RecipeSortLastCooked:
+		sort.SliceStable(cards, func(i, j int) bool {
+			a, b := cards[i].CookStats.LastCooked, cards[j].CookStats.LastCooked
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return a.After(*b)
+		})
+	}
+}
+
+func parseRecipeFilter(r *http.Request) models.RecipeFilter {
+	var filter models.RecipeFilter
+	query := r.URL.Query()
+
+	for _, name := range query["free"] {
+		if allergen, ok := dietary.ParseAllergen(name); ok {
+			filter.FreeOf |= allergen
+		}
+	}
+	for _, name := range query["diet"] {
+		if diet, ok := dietary.ParseDiet(name); ok {
+			filter.Diets |= diet
+		}
+	}
+
+	return filter
+}
+
 type RecipeCreateForm struct {
 	Title string `schema:"title"`
 }
@@ -176,7 +526,7 @@ func (rc *Recipes) Create(rw http.ResponseWriter, r *http.Request) {
 		Title:  form.Title,
 	}
 
-	err := rc.rs.Create(&recipe)
+	err := rc.rs.Create(r.Context(), &recipe)
 	if err != nil {
 		vd.SetAlertDanger(err)
 		rc.NewView.Render(rw, r, vd)
@@ -185,7 +535,7 @@ func (rc *Recipes) Create(rw http.ResponseWriter, r *http.Request) {
 
 	url, err := rc.router.Get(RouteRecipeEdit).URL("id", fmt.Sprintf("%v", recipe.ID))
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		http.Redirect(rw, r, "/recipes", http.StatusFound)
 		return
 	}
@@ -198,14 +548,9 @@ func (rc *Recipes) Edit(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user := context.User(r.Context())
-	if recipe.UserID != user.ID {
-		http.Error(rw, "Recipe not found", http.StatusNotFound)
-		return
-	}
-
 	var vd views.Data
 	vd.Yield = recipe
+	rc.warnDuplicates(r, recipe, &vd)
 	rc.EditView.Render(rw, r, vd)
 }
 
@@ -214,6 +559,7 @@ type RecipeUpdateForm struct {
 	Description  string `schema:"description"`
 	Ingredients  string `schema:"ingredients"`
 	Instructions string `schema:"instructions"`
+	Servings     int    `schema:"servings"`
 }
 
 func (rc *Recipes) Update(rw http.ResponseWriter, r *http.Request) {
@@ -231,57 +577,69 @@ func (rc *Recipes) Update(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user := context.User(r.Context())
-	if recipe.UserID != user.ID {
-		http.Error(rw, "Recipe not found", http.StatusNotFound)
-		return
-	}
-
 	recipe.Title = form.Title
 	recipe.Description = form.Description
 	recipe.Ingredients = form.Ingredients
 	recipe.Instructions = form.Instructions
+	recipe.Servings = form.Servings
 
-	err = rc.rs.Update(recipe)
+	err = rc.rs.Update(r.Context(), recipe)
 	if err != nil {
 		vd.SetAlertDanger(err)
 		rc.EditView.Render(rw, r, vd)
 		return
 	}
 
+	if rc.warnDuplicates(r, recipe, &vd) {
+		vd.Yield = recipe
+		rc.EditView.Render(rw, r, vd)
+		return
+	}
+
 	url, err := rc.router.Get(RouteRecipeShow).URL("id", fmt.Sprintf("%v", recipe.ID))
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		http.Redirect(rw, r, "/recipes", http.StatusFound)
 		return
 	}
 	http.Redirect(rw, r, url.Path, http.StatusFound)
 }
 
-func (rc *Recipes) getRecipe(rw http.ResponseWriter, r *http.Request) (*models.Recipe, error) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-
-	recipeID, err := strconv.Atoi(idStr)
-	if err != nil {
-		log.Println(err)
-		http.Error(rw, "Invalid recipe ID", http.StatusNotFound)
-		return nil, err
+func (rc *Recipes) setForks(r *http.Request, recipe *models.Recipe) error {
+	user := context.User(r.Context())
+	if recipe.ForkedFromID != nil {
+		forkedFrom, err := rc.rs.ByID(r.Context(), *recipe.ForkedFromID)
+		switch err {
+		case nil:
+			allowed, err := rc.policy.Can(r.Context(), user, policy.ActionView, forkedFrom)
+			if err != nil {
+				return err
+			}
+			if allowed {
+				recipe.ForkedFrom = forkedFrom
+			}
+		case models.ErrNotFound:
+		default:
+			return err
+		}
 	}
 
-	recipe, err := rc.rs.ByID(uint(recipeID))
+	forks, err := rc.rs.ByForkedFromID(r.Context(), recipe.ID)
 	if err != nil {
-		if err == models.ErrNotFound {
-			http.Error(rw, "Recipe not found", http.StatusNotFound)
-			return nil, err
-		}
+		return err
+	}
+	recipe.Forks, err = viewableRecipes(r, rc.policy, user, forks)
+	return err
+}
 
-		log.Println(err)
-		http.Error(rw, "Something went wrong when trying to find recipe", http.StatusInternalServerError)
-		return nil, err
+func (rc *Recipes) getRecipe(rw http.ResponseWriter, r *http.Request) (*models.Recipe, error) {
+	recipe := context.Recipe(r.Context())
+	if recipe == nil {
+		http.Error(rw, "Recipe not found", http.StatusNotFound)
+		return nil, models.ErrNotFound
 	}
 
-	images, err := rc.is.ByRecipeID(recipe.ID)
+	images, err := rc.is.ByRecipeID(r.Context(), recipe.ID)
 	if err != nil {
 		http.Error(rw, "Failed to fetch recipe images", http.StatusInternalServerError)
 		return nil, err
@@ -289,3 +647,19 @@ func (rc *Recipes) getRecipe(rw http.ResponseWriter, r *http.Request) (*models.R
 	recipe.Images = images
 	return recipe, nil
 }
+
+type MarkdownPreviewForm struct {
+	Source string `schema:"source"`
+}
+
+func (rc *Recipes) Preview(rw http.ResponseWriter, r *http.Request) {
+	var form MarkdownPreviewForm
+
+	if err := parseForm(r, &form); err != nil {
+		http.Error(rw, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(rw, markdown.Render(form.Source))
+}
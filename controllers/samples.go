@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/mpanelo/gocookit/dietary"
+	"github.com/mpanelo/gocookit/ingredient"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/nutrition"
+)
+
+var sampleTime = time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+func sampleUser() models.User {
+	user := models.User{Name: "Sample User", Email: "sample@gocookit.io"}
+	user.ID = 1
+	return user
+}
+
+func sampleRecipe() *models.Recipe {
+	workspaceID := uint(1)
+	forkedFromID := uint(2)
+
+	recipe := &models.Recipe{
+		UserID:          1,
+		WorkspaceID:     &workspaceID,
+		Title:           "Sample Recipe",
+		Description:     "A *sample* description.",
+		Ingredients:     "2 cups flour\n1 tsp salt",
+		Instructions:    "1. Mix.\n2. Bake.",
+		Servings:        4,
+		Allergens:       dietary.Gluten,
+		Diets:           dietary.Vegetarian,
+		DietaryAnalyzed: true,
+		ForkedFromID:    &forkedFromID,
+		ForkedFrom:      &models.Recipe{Title: "Original Recipe"},
+		Forks:           []models.Recipe{{UserID: 2, Title: "Forked Recipe"}},
+		Images:          []models.Image{{RecipeID: 1, Filename: "sample.jpg"}},
+	}
+	recipe.ID = 1
+	recipe.CreatedAt = sampleTime
+	recipe.UpdatedAt = sampleTime
+	recipe.ForkedFrom.ID = forkedFromID
+	recipe.Forks[0].ID = 3
+	return recipe
+}
+
+func sampleRecipes() []models.Recipe {
+	return []models.Recipe{*sampleRecipe()}
+}
+
+func sampleCookStats() models.CookStats {
+	lastCooked := sampleTime
+	return models.CookStats{RecipeID: 1, Count: 2, AverageRating: 4.5, LastCooked: &lastCooked}
+}
+
+func sampleComment() models.Comment {
+	parentID := uint(1)
+	comment := models.Comment{RecipeID: 1, UserID: 1, ParentID: &parentID, Body: "Sample *comment*", User: sampleUser()}
+	comment.ID = 2
+	comment.CreatedAt = sampleTime
+	return comment
+}
+
+func sampleCollection() *models.Collection {
+	collection := &models.Collection{
+		UserID:      1,
+		Title:       "Sample Collection",
+		Description: "A sample collection.",
+		Recipes:     sampleRecipes(),
+	}
+	collection.ID = 1
+	return collection
+}
+
+func sampleWorkspace() *models.Workspace {
+	workspace := &models.Workspace{
+		UserID: 1,
+		Name:   "Sample Workspace",
+		Members: []models.Membership{
+			{WorkspaceID: 1, UserID: 1, Role: models.RoleOwner, User: sampleUser()},
+		},
+		Recipes: sampleRecipes(),
+	}
+	workspace.ID = 1
+	return workspace
+}
+
+func sampleLabel() *nutrition.Label {
+	food := sampleFood()
+	return &nutrition.Label{
+		Servings: 4,
+		Lines: []nutrition.Line{
+			{Ingredient: ingredient.Ingredient{Quantity: 2, Unit: "cup", Name: "flour"}, Food: &food, Grams: 250},
+			{Ingredient: ingredient.Ingredient{Quantity: 1, Unit: "tsp", Name: "saffron"}, Manual: true},
+		},
+		Total:      nutrition.Nutrients{Calories: 910, Protein: 25},
+		PerServing: nutrition.Nutrients{Calories: 227.5, Protein: 6.25},
+	}
+}
+
+func sampleFood() nutrition.Food {
+	return nutrition.Food{ID: "flour", Name: "Flour", Aliases: []string{"all-purpose flour"}, Per100g: nutrition.Nutrients{Calories: 364}}
+}
+
+func sampleShoppingList() *models.ShoppingList {
+	list := &models.ShoppingList{
+		UserID: 1,
+		Title:  "Sample List",
+		Items: []models.ShoppingListItem{
+			{ShoppingListID: 1, Name: "flour", Quantity: 2, Unit: "cup", Aisle: "Baking"},
+		},
+	}
+	list.ID = 1
+	list.Items[0].ID = 1
+	return list
+}
+
+func sampleMealPlanDays(inMonth bool) []MealPlanDay {
+	entry := models.MealPlanEntry{UserID: 1, RecipeID: 1, Date: sampleTime, Slot: models.MealSlotDinner, Servings: 2, Recipe: *sampleRecipe()}
+	entry.ID = 1
+
+	var slots []MealPlanSlot
+	for _, slot := range models.MealSlots {
+		slots = append(slots, MealPlanSlot{Slot: slot, Entries: []models.MealPlanEntry{entry}})
+	}
+	return []MealPlanDay{{Date: sampleTime, InMonth: inMonth, Slots: slots}}
+}
+
+func sampleRecipeShow() *RecipeShowData {
+	reply := RecipeComment{Comment: sampleComment(), CanEdit: true, CanModerate: true}
+	comment := RecipeComment{Comment: sampleComment(), Replies: []RecipeComment{reply}, CanEdit: true, CanModerate: true}
+	comment.ParentID = nil
+	comment.ID = 1
+
+	cookLog := models.CookLog{UserID: 1, RecipeID: 1, CookedOn: sampleTime, Rating: 4, Notes: "Sample notes", Photo: "dinner.jpg", User: sampleUser()}
+	cookLog.ID = 1
+
+	return &RecipeShowData{
+		Recipe:      sampleRecipe(),
+		Collections: []RecipeCollection{{Collection: *sampleCollection(), Contains: true}},
+		Workspaces:  []models.Workspace{*sampleWorkspace()},
+		Nutrition:   sampleLabel(),
+		CookStats:   sampleCookStats(),
+		CookLogs:    []models.CookLog{cookLog},
+		Comments:    []RecipeComment{comment},
+		Favorite:    true,
+		Related:     sampleRecipes(),
+		UserID:      1,
+		Today:       sampleTime.Format(models.DateLayout),
+		CanEdit:     true,
+		CanManage:   true,
+	}
+}
+
+func sampleRecipeIndex() *RecipeIndexData {
+	return &RecipeIndexData{
+		Recipes:   []RecipeCard{{Recipe: *sampleRecipe(), CookStats: sampleCookStats()}},
+		Filter:    models.RecipeFilter{FreeOf: dietary.Gluten, Diets: dietary.Vegetarian},
+		Sort:      "title",
+		Recent:    sampleRecipes(),
+		Allergens: dietary.Allergens,
+		Diets:     dietary.Diets,
+	}
+}
+
+func sampleDuplicates() [][]models.Recipe {
+	return [][]models.Recipe{{*sampleRecipe(), *sampleRecipe()}}
+}
+
+func sampleMealPlanWeek() *MealPlanWeek {
+	return &MealPlanWeek{
+		Start:    sampleTime,
+		Previous: sampleTime.AddDate(0, 0, -7),
+		Next:     sampleTime.AddDate(0, 0, 7),
+		Slots:    models.MealSlots,
+		Days:     sampleMealPlanDays(true),
+		Recipes:  sampleRecipes(),
+	}
+}
+
+func sampleMealPlanMonth() *MealPlanMonth {
+	return &MealPlanMonth{
+		Month:    sampleTime,
+		Previous: sampleTime.AddDate(0, -1, 0),
+		Next:     sampleTime.AddDate(0, 1, 0),
+		Weeks:    [][]MealPlanDay{sampleMealPlanDays(true), sampleMealPlanDays(false)},
+	}
+}
+
+func sampleNutritionEdit() *NutritionEditData {
+	return &NutritionEditData{
+		Recipe: sampleRecipe(),
+		Label:  sampleLabel(),
+		Foods:  []nutrition.Food{sampleFood()},
+	}
+}
+
+func samplePantryItems() []models.PantryItem {
+	expiresAt := sampleTime
+	item := models.PantryItem{UserID: 1, Name: "flour", Quantity: 2, Unit: "cup", ExpiresAt: &expiresAt}
+	item.ID = 1
+	return []models.PantryItem{item}
+}
+
+func sampleCookable() *CookableData {
+	return &CookableData{
+		Matches: []models.RecipeMatch{{
+			Recipe:  *sampleRecipe(),
+			Have:    []ingredient.Ingredient{{Quantity: 2, Unit: "cup", Name: "flour"}},
+			Missing: []ingredient.Ingredient{{Quantity: 1, Unit: "tsp", Name: "salt"}},
+		}},
+		ShoppingLists: []models.ShoppingList{*sampleShoppingList()},
+	}
+}
+
+func sampleWorkspaceShow() *WorkspaceShowData {
+	return &WorkspaceShowData{
+		Workspace:   sampleWorkspace(),
+		Roles:       models.Roles,
+		InviteRoles: models.InvitationRoles,
+		CanManage:   true,
+		InviteURL:   "http://localhost:3000/invitations/sample-token",
+	}
+}
+
+func sampleInvitation() *models.Invitation {
+	invitation := &models.Invitation{
+		WorkspaceID: 1,
+		CreatedByID: 1,
+		Role:        models.RoleEditor,
+		Token:       "sample-token",
+		ExpiresAt:   sampleTime.AddDate(0, 0, 7),
+		Workspace:   *sampleWorkspace(),
+	}
+	invitation.ID = 1
+	return invitation
+}
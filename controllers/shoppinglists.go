@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/ingredient"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+const RouteShoppingListShow = "routeShoppingListShow"
+
+type ShoppingLists struct {
+	NewView   *views.View
+	IndexView *views.View
+	ShowView  *views.View
+	ss        models.ShoppingListService
+	rs        models.RecipeService
+	policy    *policy.Policy
+	router    *mux.Router
+}
+
+func NewShoppingLists(vs *views.Registry, ss models.ShoppingListService, rs models.RecipeService, p *policy.Policy, router *mux.Router) *ShoppingLists {
+	return &ShoppingLists{
+		NewView:   vs.NewView("shoppinglists/new", sampleRecipes()),
+		IndexView: vs.NewView("shoppinglists/index", []models.ShoppingList{*sampleShoppingList()}),
+		ShowView:  vs.NewView("shoppinglists/show", sampleShoppingList()),
+		ss:        ss,
+		rs:        rs,
+		policy:    p,
+		router:    router,
+	}
+}
+
+func (sc *ShoppingLists) Index(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	user := context.User(r.Context())
+
+	lists, err := sc.ss.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		sc.IndexView.Render(rw, r, vd)
+		return
+	}
+
+	vd.Yield = lists
+	sc.IndexView.Render(rw, r, vd)
+}
+
+func (sc *ShoppingLists) New(rw http.ResponseWriter, r *http.Request) {
+	sc.renderNew(rw, r, views.Data{})
+}
+
+type ShoppingListForm struct {
+	Title   string                   `schema:"title"`
+	Recipes []ShoppingListRecipeForm `schema:"recipes"`
+}
+
+type ShoppingListRecipeForm struct {
+	ID       uint `schema:"id"`
+	Selected bool `schema:"selected"`
+	Servings int  `schema:"servings"`
+}
+
+func (sc *ShoppingLists) Create(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form ShoppingListForm
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		sc.renderNew(rw, r, vd)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	list := models.ShoppingList{
+		UserID: user.ID,
+		Title:  form.Title,
+	}
+
+	var ingredients []ingredient.Ingredient
+	for _, selection := range form.Recipes {
+		if !selection.Selected {
+			continue
+		}
+
+		recipe, err := sc.rs.ByID(r.Context(), selection.ID)
+		if err != nil {
+			vd.SetAlertDanger(err)
+			sc.renderNew(rw, r, vd)
+			return
+		}
+
+		if err := authorize(rw, r, sc.policy, policy.ActionView, recipe); err != nil {
+			return
+		}
+
+		ingredients = append(ingredients, recipe.ScaledIngredients(selection.Servings)...)
+	}
+
+	if len(ingredients) == 0 {
+		vd.SetAlertDanger(models.ErrShoppingListEmpty)
+		sc.renderNew(rw, r, vd)
+		return
+	}
+	list.AddIngredients(ingredients)
+
+	err := sc.ss.Create(r.Context(), &list)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		sc.renderNew(rw, r, vd)
+		return
+	}
+
+	sc.redirectToShow(rw, r, list.ID)
+}
+
+func (sc *ShoppingLists) Show(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	list, err := sc.getShoppingList(rw, r)
+	if err != nil {
+		return
+	}
+
+	vd.Yield = list
+	sc.ShowView.Render(rw, r, vd)
+}
+
+func (sc *ShoppingLists) ToggleItem(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	list, err := sc.getShoppingList(rw, r)
+	if err != nil {
+		return
+	}
+
+	itemID, err := strconv.Atoi(mux.Vars(r)["itemID"])
+	if err != nil {
+		http.Error(rw, "Invalid item ID", http.StatusNotFound)
+		return
+	}
+
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.ID != uint(itemID) {
+			continue
+		}
+
+		item.Checked = !item.Checked
+		err = sc.ss.SaveItem(r.Context(), item)
+		if err != nil {
+			vd.Yield = list
+			vd.SetAlertDanger(err)
+			sc.ShowView.Render(rw, r, vd)
+			return
+		}
+
+		sc.redirectToShow(rw, r, list.ID)
+		return
+	}
+
+	http.Error(rw, "Item not found", http.StatusNotFound)
+}
+
+func (sc *ShoppingLists) Delete(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	list, err := sc.getShoppingList(rw, r)
+	if err != nil {
+		return
+	}
+
+	err = sc.ss.Delete(r.Context(), list.ID)
+	if err != nil {
+		vd.Yield = list
+		vd.SetAlertDanger(err)
+		sc.ShowView.Render(rw, r, vd)
+		return
+	}
+
+	http.Redirect(rw, r, "/shoppinglists", http.StatusFound)
+}
+
+func (sc *ShoppingLists) Export(rw http.ResponseWriter, r *http.Request) {
+	list, err := sc.getShoppingList(rw, r)
+	if err != nil {
+		return
+	}
+
+	body, contentType, ext := list.Text(), "text/plain; charset=utf-8", "txt"
+	if r.URL.Query().Get("format") == "markdown" {
+		body, contentType, ext = list.Markdown(), "text/markdown; charset=utf-8", "md"
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"shopping-list-%d.%s\"", list.ID, ext))
+	fmt.Fprint(rw, body)
+}
+
+func (sc *ShoppingLists) renderNew(rw http.ResponseWriter, r *http.Request, vd views.Data) {
+	user := context.User(r.Context())
+
+	recipes, err := sc.rs.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+
+	vd.Yield = recipes
+	sc.NewView.Render(rw, r, vd)
+}
+
+func (sc *ShoppingLists) redirectToShow(rw http.ResponseWriter, r *http.Request, id uint) {
+	url, err := sc.router.Get(RouteShoppingListShow).URL("id", fmt.Sprintf("%v", id))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/shoppinglists", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func (sc *ShoppingLists) getShoppingList(rw http.ResponseWriter, r *http.Request) (*models.ShoppingList, error) {
+	listID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid shopping list ID", http.StatusNotFound)
+		return nil, err
+	}
+
+	list, err := sc.ss.ByID(r.Context(), uint(listID))
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Shopping list not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find shopping list", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if err := authorize(rw, r, sc.policy, policy.ActionManage, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
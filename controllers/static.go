@@ -8,8 +8,8 @@ type Static struct {
 	// Contact *views.View
 }
 
-func NewStatic() *Static {
+func NewStatic(vs *views.Registry) *Static {
 	return &Static{
-		Home: views.NewView("static/home"),
+		Home: vs.NewView("static/home", nil),
 	}
 }
@@ -8,10 +8,10 @@ import (
 	"github.com/mpanelo/gocookit/views"
 )
 
-func NewUsers(us models.UserService) *Users {
+func NewUsers(vs *views.Registry, us models.UserService) *Users {
 	return &Users{
-		SignUpView: views.NewView("users/signup"),
-		SignInView: views.NewView("users/signin"),
+		SignUpView: vs.NewView("users/signup", nil),
+		SignInView: vs.NewView("users/signin", nil),
 		us:         us,
 	}
 }
@@ -44,14 +44,14 @@ func (u *Users) SignUp(rw http.ResponseWriter, r *http.Request) {
 		Password: form.Password,
 	}
 
-	err := u.us.Create(user)
+	err := u.us.Create(r.Context(), user)
 	if err != nil {
 		vd.SetAlertDanger(err)
 		u.SignUpView.Render(rw, r, vd)
 		return
 	}
 
-	err = u.setRememberTokenCookie(rw, user)
+	err = u.setRememberTokenCookie(rw, r, user)
 	if err != nil {
 		vd.SetAlertDanger(err)
 		u.SignInView.Render(rw, r, vd)
@@ -76,14 +76,14 @@ func (u *Users) SignIn(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := u.us.Authenticate(form.Email, form.Password)
+	user, err := u.us.Authenticate(r.Context(), form.Email, form.Password)
 	if err != nil {
 		vd.SetAlertDanger(err)
 		u.SignInView.Render(rw, r, vd)
 		return
 	}
 
-	err = u.setRememberTokenCookie(rw, user)
+	err = u.setRememberTokenCookie(rw, r, user)
 	if err != nil {
 		vd.SetAlertDanger(err)
 		u.SignInView.Render(rw, r, vd)
@@ -93,7 +93,7 @@ func (u *Users) SignIn(rw http.ResponseWriter, r *http.Request) {
 	http.Redirect(rw, r, "/recipes", http.StatusFound)
 }
 
-func (u *Users) setRememberTokenCookie(rw http.ResponseWriter, user *models.User) error {
+func (u *Users) setRememberTokenCookie(rw http.ResponseWriter, r *http.Request, user *models.User) error {
 	if user.Remember == "" {
 		token, err := rand.RememberToken()
 		if err != nil {
@@ -102,7 +102,7 @@ func (u *Users) setRememberTokenCookie(rw http.ResponseWriter, user *models.User
 
 		user.Remember = token
 
-		err = u.us.Update(user)
+		err = u.us.Update(r.Context(), user)
 		if err != nil {
 			return err
 		}
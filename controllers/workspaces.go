@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
+	"github.com/mpanelo/gocookit/views"
+)
+
+const (
+	RouteWorkspaceShow  = "routeWorkspaceShow"
+	RouteInvitationShow = "routeInvitationShow"
+)
+
+type Workspaces struct {
+	NewView        *views.View
+	IndexView      *views.View
+	ShowView       *views.View
+	InvitationView *views.View
+	ws             models.WorkspaceService
+	rs             models.RecipeService
+	policy         *policy.Policy
+	router         *mux.Router
+}
+
+type WorkspaceShowData struct {
+	*models.Workspace
+	Roles       []models.Role
+	InviteRoles []models.Role
+	CanManage   bool
+	InviteURL   string
+}
+
+func NewWorkspaces(vs *views.Registry, ws models.WorkspaceService, rs models.RecipeService, p *policy.Policy, router *mux.Router) *Workspaces {
+	return &Workspaces{
+		NewView:        vs.NewView("workspaces/new", nil),
+		IndexView:      vs.NewView("workspaces/index", []models.Workspace{*sampleWorkspace()}),
+		ShowView:       vs.NewView("workspaces/show", sampleWorkspaceShow()),
+		InvitationView: vs.NewView("workspaces/invitation", sampleInvitation()),
+		ws:             ws,
+		rs:             rs,
+		policy:         p,
+		router:         router,
+	}
+}
+
+func (wc *Workspaces) Index(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	user := context.User(r.Context())
+
+	workspaces, err := wc.ws.ByUserID(r.Context(), user.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.IndexView.Render(rw, r, vd)
+		return
+	}
+
+	vd.Yield = workspaces
+	wc.IndexView.Render(rw, r, vd)
+}
+
+type WorkspaceForm struct {
+	Name string `schema:"name"`
+}
+
+func (wc *Workspaces) Create(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form WorkspaceForm
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		wc.NewView.Render(rw, r, vd)
+		return
+	}
+
+	user := context.User(r.Context())
+
+	workspace := models.Workspace{
+		UserID: user.ID,
+		Name:   form.Name,
+	}
+
+	err := wc.ws.Create(r.Context(), &workspace)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.NewView.Render(rw, r, vd)
+		return
+	}
+
+	wc.redirectToShow(rw, r, workspace.ID)
+}
+
+func (wc *Workspaces) Show(rw http.ResponseWriter, r *http.Request) {
+	workspace, err := wc.getWorkspace(rw, r, policy.ActionView)
+	if err != nil {
+		return
+	}
+
+	wc.renderShow(rw, r, workspace, views.Data{}, "")
+}
+
+func (wc *Workspaces) Update(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form WorkspaceForm
+
+	workspace, err := wc.getWorkspace(rw, r, policy.ActionManage)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	workspace.Name = form.Name
+
+	err = wc.ws.Update(r.Context(), workspace)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	wc.redirectToShow(rw, r, workspace.ID)
+}
+
+type InvitationForm struct {
+	Role models.Role `schema:"role"`
+}
+
+func (wc *Workspaces) Invite(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form InvitationForm
+
+	workspace, err := wc.getWorkspace(rw, r, policy.ActionManage)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	user := context.User(r.Context())
+
+	invitation, err := wc.ws.Invite(r.Context(), workspace.ID, user.ID, form.Role)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	url, err := wc.router.Get(RouteInvitationShow).URL("token", invitation.Token)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	vd.SetSuccess(fmt.Sprintf("Invite link created for a new %s. It expires on %s.",
+		invitation.Role, invitation.ExpiresAt.Format("Jan 2, 2006")))
+	wc.renderShow(rw, r, workspace, vd, absoluteURL(r, url.Path))
+}
+
+type MembershipForm struct {
+	Role models.Role `schema:"role"`
+}
+
+func (wc *Workspaces) UpdateMember(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+	var form MembershipForm
+
+	workspace, err := wc.getWorkspace(rw, r, policy.ActionManage)
+	if err != nil {
+		return
+	}
+
+	membership, err := wc.getMembership(rw, r, workspace)
+	if err != nil {
+		return
+	}
+
+	if err := parseForm(r, &form); err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	membership.Role = form.Role
+
+	err = wc.ws.SaveMembership(r.Context(), membership)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	wc.redirectToShow(rw, r, workspace.ID)
+}
+
+func (wc *Workspaces) RemoveMember(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	workspace, err := wc.getWorkspace(rw, r, policy.ActionView)
+	if err != nil {
+		return
+	}
+
+	membership, err := wc.getMembership(rw, r, workspace)
+	if err != nil {
+		return
+	}
+
+	user := context.User(r.Context())
+	if membership.UserID != user.ID {
+		if err := authorize(rw, r, wc.policy, policy.ActionManage, workspace); err != nil {
+			return
+		}
+	}
+
+	err = wc.ws.RemoveMembership(r.Context(), workspace.ID, membership.UserID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.renderShow(rw, r, workspace, vd, "")
+		return
+	}
+
+	if membership.UserID == user.ID {
+		http.Redirect(rw, r, "/workspaces", http.StatusFound)
+		return
+	}
+	wc.redirectToShow(rw, r, workspace.ID)
+}
+
+func (wc *Workspaces) Invitation(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	invitation, err := wc.ws.InvitationByToken(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.InvitationView.Render(rw, r, vd)
+		return
+	}
+
+	invitation.Token = mux.Vars(r)["token"]
+	vd.Yield = invitation
+	wc.InvitationView.Render(rw, r, vd)
+}
+
+func (wc *Workspaces) AcceptInvitation(rw http.ResponseWriter, r *http.Request) {
+	var vd views.Data
+
+	user := context.User(r.Context())
+
+	membership, err := wc.ws.AcceptInvitation(r.Context(), mux.Vars(r)["token"], user)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.InvitationView.Render(rw, r, vd)
+		return
+	}
+
+	wc.redirectToShow(rw, r, membership.WorkspaceID)
+}
+
+func (wc *Workspaces) renderShow(rw http.ResponseWriter, r *http.Request, workspace *models.Workspace, vd views.Data, inviteURL string) {
+	data := WorkspaceShowData{
+		Workspace:   workspace,
+		Roles:       models.Roles,
+		InviteRoles: models.InvitationRoles,
+		InviteURL:   inviteURL,
+	}
+	vd.Yield = &data
+
+	members, err := wc.ws.Members(r.Context(), workspace.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.ShowView.Render(rw, r, vd)
+		return
+	}
+	workspace.Members = members
+
+	recipes, err := wc.rs.ByWorkspaceID(r.Context(), workspace.ID)
+	if err != nil {
+		vd.SetAlertDanger(err)
+		wc.ShowView.Render(rw, r, vd)
+		return
+	}
+	workspace.Recipes = recipes
+
+	data.CanManage, err = wc.policy.Can(r.Context(), context.User(r.Context()), policy.ActionManage, workspace)
+	if err != nil {
+		vd.SetAlertDanger(err)
+	}
+
+	wc.ShowView.Render(rw, r, vd)
+}
+
+func (wc *Workspaces) redirectToShow(rw http.ResponseWriter, r *http.Request, id uint) {
+	url, err := wc.router.Get(RouteWorkspaceShow).URL("id", fmt.Sprintf("%v", id))
+	if err != nil {
+		logError(r, err)
+		http.Redirect(rw, r, "/workspaces", http.StatusFound)
+		return
+	}
+	http.Redirect(rw, r, url.Path, http.StatusFound)
+}
+
+func (wc *Workspaces) getMembership(rw http.ResponseWriter, r *http.Request, workspace *models.Workspace) (*models.Membership, error) {
+	userID, err := strconv.Atoi(mux.Vars(r)["userID"])
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid member ID", http.StatusNotFound)
+		return nil, err
+	}
+
+	membership, err := wc.ws.Membership(r.Context(), workspace.ID, uint(userID))
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Member not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find member", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+func (wc *Workspaces) getWorkspace(rw http.ResponseWriter, r *http.Request, action policy.Action) (*models.Workspace, error) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	workspaceID, err := strconv.Atoi(idStr)
+	if err != nil {
+		logError(r, err)
+		http.Error(rw, "Invalid workspace ID", http.StatusNotFound)
+		return nil, err
+	}
+
+	workspace, err := wc.ws.ByID(r.Context(), uint(workspaceID))
+	if err != nil {
+		if err == models.ErrNotFound {
+			http.Error(rw, "Workspace not found", http.StatusNotFound)
+			return nil, err
+		}
+
+		logError(r, err)
+		http.Error(rw, "Something went wrong when trying to find workspace", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if err := authorize(rw, r, wc.policy, action, workspace); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
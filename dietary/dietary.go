@@ -0,0 +1,364 @@
+package dietary
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mpanelo/gocookit/ingredient"
+)
+
+type Allergen uint
+
+const (
+	Gluten Allergen = 1 << iota
+	Dairy
+	Nuts
+	Shellfish
+	Egg
+	Soy
+	Sesame
+)
+
+var Allergens = []Allergen{Gluten, Dairy, Nuts, Shellfish, Egg, Soy, Sesame}
+
+var allergenNames = map[Allergen]string{
+	Gluten:    "gluten",
+	Dairy:     "dairy",
+	Nuts:      "nuts",
+	Shellfish: "shellfish",
+	Egg:       "egg",
+	Soy:       "soy",
+	Sesame:    "sesame",
+}
+
+func (a Allergen) String() string {
+	return allergenNames[a]
+}
+
+func (a Allergen) Has(flag Allergen) bool {
+	return a&flag != 0
+}
+
+func (a Allergen) List() []Allergen {
+	var list []Allergen
+	for _, flag := range Allergens {
+		if a.Has(flag) {
+			list = append(list, flag)
+		}
+	}
+	return list
+}
+
+func ParseAllergen(name string) (Allergen, bool) {
+	for flag, n := range allergenNames {
+		if n == name {
+			return flag, true
+		}
+	}
+	return 0, false
+}
+
+type Diet uint
+
+const (
+	Vegan Diet = 1 << iota
+	Vegetarian
+	Keto
+	HalalFriendly
+)
+
+var Diets = []Diet{Vegan, Vegetarian, Keto, HalalFriendly}
+
+var dietNames = map[Diet]string{
+	Vegan:         "vegan",
+	Vegetarian:    "vegetarian",
+	Keto:          "keto",
+	HalalFriendly: "halal-friendly",
+}
+
+func (d Diet) String() string {
+	return dietNames[d]
+}
+
+func (d Diet) Has(flag Diet) bool {
+	return d&flag == flag
+}
+
+func (d Diet) List() []Diet {
+	var list []Diet
+	for _, flag := range Diets {
+		if d.Has(flag) {
+			list = append(list, flag)
+		}
+	}
+	return list
+}
+
+func ParseDiet(name string) (Diet, bool) {
+	for flag, n := range dietNames {
+		if n == name {
+			return flag, true
+		}
+	}
+	return 0, false
+}
+
+type trait uint
+
+const (
+	animal trait = 1 << iota
+	meat
+	haram
+	carbs
+)
+
+type entry struct {
+	allergens Allergen
+	traits    trait
+}
+
+var dictionary = map[string]entry{
+	"gluten free flour": {0, carbs}, "gluten free pasta": {0, carbs}, "gluten free bread": {0, carbs},
+	"almond flour": {Nuts, 0}, "coconut flour": {0, 0}, "rice flour": {0, carbs},
+	"corn tortilla": {0, carbs}, "rice noodle": {0, carbs}, "buckwheat": {0, carbs},
+	"coconut milk": {0, 0}, "coconut cream": {0, 0}, "oat milk": {0, carbs},
+	"almond milk": {Nuts, 0}, "soy milk": {Soy, 0}, "cashew milk": {Nuts, 0},
+	"peanut butter": {Nuts, 0}, "almond butter": {Nuts, 0}, "cocoa butter": {0, 0},
+	"cream of tartar": {0, 0}, "butter bean": {0, carbs}, "vegan butter": {0, 0},
+	"vegan cheese": {0, 0}, "vegan mayo": {0, 0}, "vegan mayonnaise": {0, 0},
+	"nutritional yeast": {0, 0}, "water chestnut": {0, 0}, "oyster mushroom": {0, 0},
+	"vegetable broth": {0, 0}, "vegetable stock": {0, 0}, "green bean": {0, 0},
+	"cauliflower rice": {0, 0}, "wine vinegar": {0, 0}, "rice vinegar": {0, 0},
+	"rice wine vinegar": {0, 0}, "sugar free": {0, 0},
+
+	"wheat": {Gluten, carbs}, "flour": {Gluten, carbs}, "bread": {Gluten, carbs},
+	"breadcrumb": {Gluten, carbs}, "panko": {Gluten, carbs}, "pasta": {Gluten, carbs},
+	"spaghetti": {Gluten, carbs}, "penne": {Gluten, carbs}, "macaroni": {Gluten, carbs},
+	"linguine": {Gluten, carbs}, "fettuccine": {Gluten, carbs}, "lasagna": {Gluten, carbs},
+	"noodle": {Gluten, carbs}, "couscous": {Gluten, carbs}, "barley": {Gluten, carbs},
+	"rye": {Gluten, carbs}, "semolina": {Gluten, carbs}, "bulgur": {Gluten, carbs},
+	"farro": {Gluten, carbs}, "seitan": {Gluten, 0}, "cracker": {Gluten, carbs},
+	"tortilla": {Gluten, carbs}, "pita": {Gluten, carbs}, "bagel": {Gluten, carbs},
+	"baguette": {Gluten, carbs}, "croissant": {Gluten | Dairy, animal | carbs},
+	"bun": {Gluten, carbs}, "pie crust": {Gluten, carbs}, "puff pastry": {Gluten | Dairy, animal | carbs},
+	"beer": {Gluten, haram | carbs}, "malt": {Gluten, carbs},
+
+	"milk": {Dairy, animal | carbs}, "butter": {Dairy, animal}, "cream": {Dairy, animal},
+	"cheese": {Dairy, animal}, "cheddar": {Dairy, animal}, "parmesan": {Dairy, animal},
+	"parmigiano": {Dairy, animal}, "mozzarella": {Dairy, animal}, "ricotta": {Dairy, animal},
+	"feta": {Dairy, animal}, "mascarpone": {Dairy, animal}, "yogurt": {Dairy, animal},
+	"yoghurt": {Dairy, animal}, "ghee": {Dairy, animal}, "buttermilk": {Dairy, animal | carbs},
+	"whey": {Dairy, animal}, "casein": {Dairy, animal}, "custard": {Dairy | Egg, animal | carbs},
+	"half and half": {Dairy, animal}, "ice cream": {Dairy, animal | carbs},
+
+	"nut": {Nuts, 0}, "almond": {Nuts, 0}, "walnut": {Nuts, 0}, "pecan": {Nuts, 0},
+	"cashew": {Nuts, 0}, "pistachio": {Nuts, 0}, "hazelnut": {Nuts, 0},
+	"macadamia": {Nuts, 0}, "peanut": {Nuts, 0}, "pine nut": {Nuts, 0},
+	"praline": {Nuts, carbs}, "marzipan": {Nuts, carbs}, "nutella": {Nuts | Dairy, animal | carbs},
+
+	"shrimp": {Shellfish, animal | meat}, "prawn": {Shellfish, animal | meat},
+	"crab": {Shellfish, animal | meat}, "lobster": {Shellfish, animal | meat},
+	"scallop": {Shellfish, animal | meat}, "clam": {Shellfish, animal | meat},
+	"mussel": {Shellfish, animal | meat}, "oyster": {Shellfish, animal | meat},
+	"crawfish": {Shellfish, animal | meat}, "crayfish": {Shellfish, animal | meat},
+	"langoustine": {Shellfish, animal | meat}, "oyster sauce": {Shellfish, animal | meat},
+
+	"egg": {Egg, animal}, "egg white": {Egg, animal}, "egg yolk": {Egg, animal},
+	"mayonnaise": {Egg, animal}, "mayo": {Egg, animal}, "meringue": {Egg, animal | carbs},
+	"aioli": {Egg, animal},
+
+	"soy": {Soy, 0}, "soybean": {Soy, 0}, "soy sauce": {Soy | Gluten, 0},
+	"tofu": {Soy, 0}, "tempeh": {Soy, 0}, "edamame": {Soy, 0}, "miso": {Soy, 0},
+	"tamari": {Soy, 0},
+
+	"sesame": {Sesame, 0}, "sesame oil": {Sesame, 0}, "sesame seed": {Sesame, 0},
+	"tahini": {Sesame, 0}, "hummus": {Sesame, carbs},
+
+	"chicken": {0, animal | meat}, "beef": {0, animal | meat}, "lamb": {0, animal | meat},
+	"turkey": {0, animal | meat}, "veal": {0, animal | meat}, "duck": {0, animal | meat},
+	"venison": {0, animal | meat}, "sausage": {0, animal | meat}, "fish": {0, animal | meat},
+	"salmon": {0, animal | meat}, "tuna": {0, animal | meat}, "cod": {0, animal | meat},
+	"tilapia": {0, animal | meat}, "halibut": {0, animal | meat}, "anchovy": {0, animal | meat},
+	"sardine": {0, animal | meat}, "fish sauce": {0, animal | meat},
+	"worcestershire sauce": {0, animal | meat}, "broth": {0, animal | meat},
+	"stock": {0, animal | meat}, "honey": {0, animal | carbs},
+
+	"pork": {0, animal | meat | haram}, "bacon": {0, animal | meat | haram},
+	"ham": {0, animal | meat | haram}, "prosciutto": {0, animal | meat | haram},
+	"pancetta": {0, animal | meat | haram}, "chorizo": {0, animal | meat | haram},
+	"salami": {0, animal | meat | haram}, "pepperoni": {0, animal | meat | haram},
+	"lard": {0, animal | meat | haram}, "gelatin": {0, animal | meat | haram},
+	"wine": {0, haram}, "rum": {0, haram}, "brandy": {0, haram}, "vodka": {0, haram},
+	"bourbon": {0, haram}, "whiskey": {0, haram}, "sherry": {0, haram},
+	"mirin": {0, haram | carbs}, "sake": {0, haram}, "liqueur": {0, haram | carbs},
+
+	"sugar": {0, carbs}, "rice": {0, carbs}, "potato": {0, carbs},
+	"corn": {0, carbs}, "cornstarch": {0, carbs}, "oat": {0, carbs},
+	"maple syrup": {0, carbs}, "syrup": {0, carbs}, "agave": {0, carbs},
+	"molasses": {0, carbs}, "banana": {0, carbs}, "bean": {0, carbs},
+	"lentil": {0, carbs}, "chickpea": {0, carbs}, "quinoa": {0, carbs},
+	"cereal": {0, carbs}, "juice": {0, carbs}, "date": {0, carbs},
+	"raisin": {0, carbs}, "jam": {0, carbs}, "ketchup": {0, carbs},
+
+	"pesto": {Nuts | Dairy, animal}, "hoisin sauce": {Soy | Gluten | Sesame, carbs},
+	"teriyaki sauce": {Soy | Gluten, carbs}, "curry paste": {Shellfish, animal | meat},
+	"caesar dressing": {Egg | Dairy, animal | meat}, "ranch dressing": {Dairy | Egg, animal},
+	"alfredo sauce": {Dairy, animal}, "tartar sauce": {Egg, animal}, "bechamel": {Dairy | Gluten, animal | carbs},
+	"barbecue sauce": {0, animal | meat | carbs}, "gnocchi": {Gluten | Egg, animal | carbs},
+	"granola": {Nuts | Gluten, carbs}, "brioche": {Gluten | Dairy | Egg, animal | carbs},
+	"pastry": {Gluten | Dairy, animal | carbs}, "cake": {Gluten | Dairy | Egg, animal | carbs},
+	"cookie": {Gluten | Dairy | Egg, animal | carbs}, "biscuit": {Gluten | Dairy, animal | carbs},
+	"margarine": {Dairy, animal}, "chocolate": {Dairy, animal | carbs}, "sour cream": {Dairy, animal},
+
+	"salt": {0, 0}, "pepper": {0, 0}, "water": {0, 0}, "ice": {0, 0}, "oil": {0, 0},
+	"olive": {0, 0}, "vinegar": {0, 0}, "baking soda": {0, 0}, "baking powder": {0, 0},
+	"yeast": {0, 0}, "vanilla": {0, 0}, "cinnamon": {0, 0}, "cumin": {0, 0},
+	"paprika": {0, 0}, "turmeric": {0, 0}, "nutmeg": {0, 0}, "clove": {0, 0},
+	"cayenne": {0, 0}, "coriander": {0, 0}, "cardamom": {0, 0}, "curry powder": {0, 0},
+	"chili powder": {0, 0}, "garlic powder": {0, 0}, "onion powder": {0, 0},
+	"mustard": {0, 0}, "caper": {0, 0}, "cocoa": {0, carbs}, "coconut": {0, 0},
+	"coffee": {0, 0}, "tea": {0, 0}, "tomato sauce": {0, 0}, "tomato paste": {0, 0},
+	"hot sauce": {0, 0},
+
+	"onion": {0, 0}, "garlic": {0, 0}, "shallot": {0, 0}, "scallion": {0, 0},
+	"leek": {0, 0}, "tomato": {0, 0}, "carrot": {0, 0}, "celery": {0, 0},
+	"lettuce": {0, 0}, "spinach": {0, 0}, "kale": {0, 0}, "cabbage": {0, 0},
+	"broccoli": {0, 0}, "cauliflower": {0, 0}, "zucchini": {0, 0}, "cucumber": {0, 0},
+	"mushroom": {0, 0}, "avocado": {0, 0}, "lemon": {0, 0}, "lime": {0, 0},
+	"ginger": {0, 0}, "cilantro": {0, 0}, "parsley": {0, 0}, "basil": {0, 0},
+	"mint": {0, 0}, "dill": {0, 0}, "chive": {0, 0}, "thyme": {0, 0},
+	"rosemary": {0, 0}, "oregano": {0, 0}, "sage": {0, 0}, "bay leaf": {0, 0},
+	"jalapeno": {0, 0}, "chili": {0, 0}, "eggplant": {0, 0}, "asparagus": {0, 0},
+	"radish": {0, 0}, "berry": {0, 0}, "strawberry": {0, 0}, "blueberry": {0, 0},
+	"raspberry": {0, 0}, "beet": {0, carbs}, "squash": {0, carbs}, "butternut squash": {0, carbs},
+	"pumpkin": {0, carbs}, "pea": {0, carbs}, "apple": {0, carbs}, "orange": {0, carbs},
+	"pear": {0, carbs}, "mango": {0, carbs}, "pineapple": {0, carbs}, "grape": {0, carbs},
+	"peach": {0, carbs}, "cherry": {0, carbs},
+}
+
+var descriptors = []string{
+	"fresh", "freshly", "chopped", "minced", "diced", "sliced", "grated", "shredded",
+	"crushed", "ground", "dried", "dry", "whole", "large", "small", "medium", "extra",
+	"virgin", "unsalted", "salted", "boneless", "skinless", "raw", "cooked", "frozen",
+	"canned", "ripe", "finely", "roughly", "coarsely", "thinly", "plus", "more",
+	"optional", "softened", "melted", "peeled", "seeded", "cubed", "halved", "quartered",
+	"beaten", "lightly", "packed", "heaping", "level", "room", "temperature", "cold",
+	"warm", "hot", "boiling", "of", "and", "or", "a", "an", "the", "for", "to", "taste",
+	"serving", "garnish", "about", "few", "handful", "light", "dark", "red", "green",
+	"yellow", "white", "black", "brown", "purple", "sweet", "smoked", "toasted", "roasted",
+	"organic", "low", "sodium", "reduced", "divided", "juiced", "zest", "zested", "leaf",
+	"stem", "sprig", "piece", "pinch", "wedge", "baby", "mixed", "plain", "granulated",
+	"powdered", "kosher", "sea", "flaky", "flake", "fine", "coarse", "instant", "active",
+	"rolled", "unsweetened", "sweetened", "pure", "extract", "powder", "all", "purpose",
+	"self", "rising", "heavy", "whipping", "flat", "curly", "italian",
+}
+
+var (
+	entries     = normalizedEntries()
+	keywords    = sortedKeywords()
+	descriptive = normalizedDescriptors()
+)
+
+func Analyze(ingredients []ingredient.Ingredient) (Allergen, Diet, bool) {
+	if len(ingredients) == 0 {
+		return 0, 0, false
+	}
+
+	var allergens Allergen
+	var traits trait
+	known := true
+	for _, ing := range ingredients {
+		a, t, ok := lookup(ing.Name)
+		allergens |= a
+		traits |= t
+		known = known && ok
+	}
+	if !known {
+		return allergens, 0, false
+	}
+
+	diets := Vegan | Vegetarian | Keto | HalalFriendly
+	if traits&animal != 0 {
+		diets &^= Vegan
+	}
+	if traits&meat != 0 {
+		diets &^= Vegetarian | Vegan
+	}
+	if traits&carbs != 0 {
+		diets &^= Keto
+	}
+	if traits&haram != 0 {
+		diets &^= HalalFriendly
+	}
+
+	return allergens, diets, true
+}
+
+func lookup(name string) (Allergen, trait, bool) {
+	var allergens Allergen
+	var traits trait
+	matched := false
+
+	text := " " + normalize(name) + " "
+	for _, keyword := range keywords {
+		term := " " + keyword + " "
+		if !strings.Contains(text, term) {
+			continue
+		}
+
+		e := entries[keyword]
+		allergens |= e.allergens
+		traits |= e.traits
+		matched = true
+		text = strings.ReplaceAll(text, term, " | ")
+	}
+
+	for _, word := range strings.Fields(text) {
+		if word != "|" && !descriptive[word] {
+			return allergens, traits, false
+		}
+	}
+	return allergens, traits, matched
+}
+
+func normalize(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i, word := range words {
+		words[i] = ingredient.Singular(word)
+	}
+	return strings.Join(words, " ")
+}
+
+func normalizedDescriptors() map[string]bool {
+	normalized := make(map[string]bool, len(descriptors))
+	for _, word := range descriptors {
+		normalized[normalize(word)] = true
+	}
+	return normalized
+}
+
+func normalizedEntries() map[string]entry {
+	normalized := make(map[string]entry, len(dictionary))
+	for keyword, e := range dictionary {
+		normalized[normalize(keyword)] = e
+	}
+	return normalized
+}
+
+func sortedKeywords() []string {
+	list := make([]string, 0, len(entries))
+	for keyword := range entries {
+		list = append(list, keyword)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i]) != len(list[j]) {
+			return len(list[i]) > len(list[j])
+		}
+		return list[i] < list[j]
+	})
+	return list
+}
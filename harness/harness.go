@@ -0,0 +1,283 @@
+package harness
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/app"
+	"github.com/mpanelo/gocookit/models"
+	"gorm.io/driver/sqlite"
+)
+
+const (
+	csrfField       = "gorilla.csrf.Token"
+	updateGoldenEnv = "GOCOOKIT_UPDATE_GOLDEN"
+)
+
+var (
+	csrfTokenRe = regexp.MustCompile(`name="` + regexp.QuoteMeta(csrfField) + `" value="([^"]*)"`)
+	requestIDRe = regexp.MustCompile(`(Request ID: <code>)[^<]+`)
+)
+
+type Harness struct {
+	Server   *httptest.Server
+	Services *models.Services
+
+	t         testing.TB
+	client    *http.Client
+	sessions  map[string]*http.Client
+	goldenDir string
+}
+
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	URL        *url.URL
+	Body       string
+}
+
+type File struct {
+	Name    string
+	Content []byte
+}
+
+func New(t testing.TB, goldenDir string) *Harness {
+	t.Helper()
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	services, err := models.NewServices(
+		models.WithDialector(sqlite.Open(filepath.Join(t.TempDir(), "gocookit.db"))),
+		models.WithLogger(func(context.Context) *slog.Logger { return logger }, false),
+		models.WithUser("harness-hmac-key", "harness-pepper"),
+		models.WithRecipe(),
+		models.WithImage(),
+		models.WithCollection(),
+		models.WithWorkspace("harness-hmac-key"),
+		models.WithMealPlan(),
+		models.WithShoppingList(),
+		models.WithPantry(),
+		models.WithNutrition(),
+		models.WithCookLog(),
+		models.WithComment(),
+		models.WithFavorite(),
+		models.WithRecommendation(),
+	)
+	if err != nil {
+		t.Fatalf("harness: services: %v", err)
+	}
+	t.Cleanup(func() { services.Close() })
+	services.Image = models.NewMemoryImageService()
+
+	if err := services.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("harness: migrate: %v", err)
+	}
+
+	handler, err := app.NewHandler(services, app.Options{
+		CSRFKey: []byte("harness-csrf-key-32-bytes-long!!"),
+		Secure:  false,
+		Logger:  logger,
+	})
+	if err != nil {
+		t.Fatalf("harness: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	h := &Harness{
+		Server:    server,
+		Services:  services,
+		t:         t,
+		goldenDir: goldenDir,
+		sessions:  make(map[string]*http.Client),
+	}
+	h.ResetSession()
+	return h
+}
+
+func (h *Harness) ResetSession() {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		h.t.Fatalf("harness: cookie jar: %v", err)
+	}
+	h.client = &http.Client{Jar: jar}
+}
+
+func (h *Harness) UseSession(name string) {
+	if client, ok := h.sessions[name]; ok {
+		h.client = client
+		return
+	}
+	h.ResetSession()
+	h.sessions[name] = h.client
+}
+
+func (h *Harness) Get(path string) *Response {
+	h.t.Helper()
+
+	return h.do(http.MethodGet, path, "", nil)
+}
+
+func (h *Harness) Post(path string, values url.Values) *Response {
+	h.t.Helper()
+
+	return h.do(http.MethodPost, path, "application/x-www-form-urlencoded", strings.NewReader(values.Encode()))
+}
+
+func (h *Harness) PostForm(formPath, action string, values url.Values) *Response {
+	h.t.Helper()
+
+	form := url.Values{}
+	for k, v := range values {
+		form[k] = v
+	}
+	form.Set(csrfField, h.csrfTokenFor(formPath))
+	return h.Post(action, form)
+}
+
+func (h *Harness) SignUp(name, email, password string) *Response {
+	h.t.Helper()
+
+	return h.PostForm("/signup", "/users", url.Values{
+		"name":     {name},
+		"email":    {email},
+		"password": {password},
+	})
+}
+
+func (h *Harness) SignIn(email, password string) *Response {
+	h.t.Helper()
+
+	return h.PostForm("/signin", "/signin", url.Values{
+		"email":    {email},
+		"password": {password},
+	})
+}
+
+func (h *Harness) UploadImages(recipeID uint, files ...File) *Response {
+	h.t.Helper()
+
+	return h.PostMultipart(fmt.Sprintf("/recipes/%d/edit", recipeID), fmt.Sprintf("/recipes/%d/images", recipeID), nil, "images", files...)
+}
+
+func (h *Harness) PostMultipart(formPath, action string, values url.Values, field string, files ...File) *Response {
+	h.t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField(csrfField, h.csrfTokenFor(formPath)); err != nil {
+		h.t.Fatalf("harness: multipart: %v", err)
+	}
+	for k, vs := range values {
+		for _, v := range vs {
+			if err := mw.WriteField(k, v); err != nil {
+				h.t.Fatalf("harness: multipart: %v", err)
+			}
+		}
+	}
+	for _, f := range files {
+		part, err := mw.CreateFormFile(field, f.Name)
+		if err != nil {
+			h.t.Fatalf("harness: multipart: %v", err)
+		}
+		if _, err := part.Write(f.Content); err != nil {
+			h.t.Fatalf("harness: multipart: %v", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		h.t.Fatalf("harness: multipart: %v", err)
+	}
+	return h.do(http.MethodPost, action, mw.FormDataContentType(), &body)
+}
+
+func (h *Harness) AssertGolden(name, body string) {
+	h.t.Helper()
+
+	got := Normalize(body)
+	path := filepath.Join(h.goldenDir, name+".golden")
+
+	if os.Getenv(updateGoldenEnv) != "" {
+		if err := os.MkdirAll(h.goldenDir, 0755); err != nil {
+			h.t.Fatalf("harness: golden: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			h.t.Fatalf("harness: golden: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatalf("harness: golden %s: %v (set %s=1 to create it)", name, err, updateGoldenEnv)
+	}
+	if got != string(want) {
+		h.t.Errorf("harness: %s does not match golden file %s (set %s=1 to update)\n--- got ---\n%s", name, path, updateGoldenEnv, got)
+	}
+}
+
+func CSRFToken(body string) string {
+	m := csrfTokenRe.FindStringSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return html.UnescapeString(m[1])
+}
+
+func Normalize(body string) string {
+	body = csrfTokenRe.ReplaceAllString(body, `name="`+csrfField+`" value="CSRF_TOKEN"`)
+	body = requestIDRe.ReplaceAllString(body, "${1}REQUEST_ID")
+	return body
+}
+
+func (h *Harness) csrfTokenFor(formPath string) string {
+	h.t.Helper()
+
+	res := h.Get(formPath)
+	token := CSRFToken(res.Body)
+	if token == "" {
+		h.t.Fatalf("harness: no CSRF token on %s (status %d)", formPath, res.StatusCode)
+	}
+	return token
+}
+
+func (h *Harness) do(method, path, contentType string, body io.Reader) *Response {
+	h.t.Helper()
+
+	req, err := http.NewRequest(method, h.Server.URL+path, body)
+	if err != nil {
+		h.t.Fatalf("harness: %s %s: %v", method, path, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		h.t.Fatalf("harness: %s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		h.t.Fatalf("harness: %s %s: %v", method, path, err)
+	}
+	return &Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		URL:        res.Request.URL,
+		Body:       string(b),
+	}
+}
@@ -0,0 +1,157 @@
+package harness_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mpanelo/gocookit/harness"
+	"github.com/mpanelo/gocookit/models"
+)
+
+var goldenDir = filepath.Join("testdata", "golden")
+
+func TestSignUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantPath string
+		golden   string
+	}{
+		{"valid", "ada@example.com", "password1", "/recipes", "signup_valid"},
+		{"password too short", "ada@example.com", "short", "/users", "signup_password_too_short"},
+		{"email invalid", "not-an-email", "password1", "/users", "signup_email_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := harness.New(t, goldenDir)
+
+			res := h.SignUp("Ada Lovelace", tt.email, tt.password)
+			if res.StatusCode != http.StatusOK || res.URL.Path != tt.wantPath {
+				t.Fatalf("SignUp() = %d %s, want %d %s", res.StatusCode, res.URL.Path, http.StatusOK, tt.wantPath)
+			}
+			h.AssertGolden(tt.golden, res.Body)
+		})
+	}
+}
+
+func TestSignIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantPath string
+		golden   string
+	}{
+		{"valid", "ada@example.com", "password1", "/recipes", "signin_valid"},
+		{"normalized email", " ADA@example.com", "password1", "/recipes", "signin_valid"},
+		{"wrong password", "ada@example.com", "password2", "/signin", "signin_invalid"},
+		{"unknown email", "bob@example.com", "password1", "/signin", "signin_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := harness.New(t, goldenDir)
+			h.SignUp("Ada Lovelace", "ada@example.com", "password1")
+			h.ResetSession()
+
+			res := h.SignIn(tt.email, tt.password)
+			if res.StatusCode != http.StatusOK || res.URL.Path != tt.wantPath {
+				t.Fatalf("SignIn() = %d %s, want %d %s", res.StatusCode, res.URL.Path, http.StatusOK, tt.wantPath)
+			}
+			h.AssertGolden(tt.golden, res.Body)
+		})
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	h := harness.New(t, goldenDir)
+	h.SignUp("Ada Lovelace", "ada@example.com", "password1")
+
+	form := url.Values{"title": {"Bread"}}
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"missing token", "", http.StatusForbidden},
+		{"forged token", "Zm9yZ2VkLXRva2VuLWZvcmdlZC10b2tlbi1mb3JnZWQtdG9rZW4=", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := url.Values{}
+			for k, v := range form {
+				values[k] = v
+			}
+			if tt.token != "" {
+				values.Set("gorilla.csrf.Token", tt.token)
+			}
+
+			res := h.Post("/recipes", values)
+			if res.StatusCode != tt.want {
+				t.Fatalf("POST /recipes = %d, want %d", res.StatusCode, tt.want)
+			}
+		})
+	}
+
+	recipes, err := h.Services.Recipe.ByUserID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ByUserID: %v", err)
+	}
+	if len(recipes) != 0 {
+		t.Fatalf("requests without a valid CSRF token created %d recipes", len(recipes))
+	}
+
+	res := h.PostForm("/recipes/new", "/recipes", form)
+	if res.StatusCode != http.StatusOK || !strings.HasSuffix(res.URL.Path, "/edit") {
+		t.Fatalf("PostForm(/recipes) = %d %s, want the edit page", res.StatusCode, res.URL.Path)
+	}
+}
+
+func TestUploadImages(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []harness.File
+		want  []models.Image
+	}{
+		{"single", []harness.File{{Name: "bread.jpg", Content: []byte("bread")}}, []models.Image{{RecipeID: 1, Filename: "bread.jpg"}}},
+		{"several", []harness.File{
+			{Name: "crumb.jpg", Content: []byte("crumb")},
+			{Name: "crust.jpg", Content: []byte("crust")},
+		}, []models.Image{{RecipeID: 1, Filename: "crumb.jpg"}, {RecipeID: 1, Filename: "crust.jpg"}}},
+		{"path stripped", []harness.File{{Name: "../2/loaf.jpg", Content: []byte("loaf")}}, []models.Image{{RecipeID: 1, Filename: "loaf.jpg"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := harness.New(t, goldenDir)
+			h.SignUp("Ada Lovelace", "ada@example.com", "password1")
+			h.PostForm("/recipes/new", "/recipes", url.Values{"title": {"Bread"}})
+
+			res := h.UploadImages(1, tt.files...)
+			if res.StatusCode != http.StatusOK || !strings.Contains(res.Body, "Images uploaded successfully") {
+				t.Fatalf("UploadImages() = %d without a success alert\n%s", res.StatusCode, res.Body)
+			}
+			h.AssertGolden("upload_"+strings.ReplaceAll(tt.name, " ", "_"), h.Get("/recipes/1/edit").Body)
+
+			images, err := h.Services.Image.ByRecipeID(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("ByRecipeID: %v", err)
+			}
+			if len(images) != len(tt.want) {
+				t.Fatalf("ByRecipeID() = %v, want %v", images, tt.want)
+			}
+			for i := range images {
+				if images[i] != tt.want[i] {
+					t.Errorf("ByRecipeID()[%d] = %v, want %v", i, images[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
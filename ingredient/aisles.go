@@ -0,0 +1,91 @@
+package ingredient
+
+import (
+	"strings"
+)
+
+const (
+	AisleProduce   = "Produce"
+	AisleMeat      = "Meat & Seafood"
+	AisleDairy     = "Dairy & Eggs"
+	AisleBakery    = "Bakery"
+	AislePantry    = "Pantry"
+	AisleSpices    = "Spices & Seasonings"
+	AisleFrozen    = "Frozen"
+	AisleBeverages = "Beverages"
+	AisleOther     = "Other"
+)
+
+var Aisles = []string{
+	AisleProduce,
+	AisleMeat,
+	AisleDairy,
+	AisleBakery,
+	AislePantry,
+	AisleSpices,
+	AisleFrozen,
+	AisleBeverages,
+	AisleOther,
+}
+
+var aisleKeywords = map[string]string{
+	"black pepper":  AisleSpices,
+	"bell pepper":   AisleProduce,
+	"chili pepper":  AisleProduce,
+	"green onion":   AisleProduce,
+	"peanut butter": AislePantry,
+	"ice cream":     AisleFrozen,
+	"frozen":        AisleFrozen,
+	"coconut milk":  AislePantry,
+	"soy sauce":     AislePantry,
+	"baking soda":   AislePantry,
+	"baking powder": AislePantry,
+
+	"onion": AisleProduce, "garlic": AisleProduce, "tomato": AisleProduce, "potato": AisleProduce,
+	"carrot": AisleProduce, "celery": AisleProduce, "lettuce": AisleProduce, "spinach": AisleProduce,
+	"kale": AisleProduce, "cabbage": AisleProduce, "broccoli": AisleProduce, "cauliflower": AisleProduce,
+	"zucchini": AisleProduce, "cucumber": AisleProduce, "mushroom": AisleProduce, "avocado": AisleProduce,
+	"lemon": AisleProduce, "lime": AisleProduce, "orange": AisleProduce, "apple": AisleProduce,
+	"banana": AisleProduce, "berry": AisleProduce, "strawberry": AisleProduce, "blueberry": AisleProduce,
+	"ginger": AisleProduce, "cilantro": AisleProduce, "parsley": AisleProduce, "basil": AisleProduce,
+	"mint": AisleProduce, "scallion": AisleProduce, "shallot": AisleProduce, "leek": AisleProduce,
+	"jalapeno": AisleProduce, "squash": AisleProduce, "pumpkin": AisleProduce, "corn": AisleProduce,
+	"pea": AisleProduce, "bean sprout": AisleProduce, "eggplant": AisleProduce, "pear": AisleProduce,
+	"chicken": AisleMeat, "beef": AisleMeat, "pork": AisleMeat, "bacon": AisleMeat, "sausage": AisleMeat,
+	"turkey": AisleMeat, "lamb": AisleMeat, "ham": AisleMeat, "fish": AisleMeat, "salmon": AisleMeat,
+	"tuna": AisleMeat, "shrimp": AisleMeat, "prawn": AisleMeat, "crab": AisleMeat, "lobster": AisleMeat,
+	"milk": AisleDairy, "butter": AisleDairy, "cheese": AisleDairy, "cream": AisleDairy,
+	"yogurt": AisleDairy, "egg": AisleDairy, "parmesan": AisleDairy, "mozzarella": AisleDairy,
+	"cheddar": AisleDairy, "sour cream": AisleDairy, "buttermilk": AisleDairy,
+	"bread": AisleBakery, "bun": AisleBakery, "tortilla": AisleBakery, "bagel": AisleBakery,
+	"pita": AisleBakery, "baguette": AisleBakery, "croissant": AisleBakery,
+	"flour": AislePantry, "sugar": AislePantry, "rice": AislePantry, "pasta": AislePantry,
+	"noodle": AislePantry, "oil": AislePantry, "vinegar": AislePantry, "honey": AislePantry,
+	"stock": AislePantry, "broth": AislePantry, "bean": AislePantry, "lentil": AislePantry,
+	"chickpea": AislePantry, "oat": AislePantry, "nut": AislePantry, "almond": AislePantry,
+	"walnut": AislePantry, "pecan": AislePantry, "chocolate": AislePantry, "yeast": AislePantry,
+	"syrup": AislePantry, "sauce": AislePantry, "ketchup": AislePantry, "mustard": AislePantry,
+	"mayonnaise": AislePantry, "cornstarch": AislePantry, "vanilla": AislePantry, "tahini": AislePantry,
+	"salt": AisleSpices, "pepper": AisleSpices, "cumin": AisleSpices, "paprika": AisleSpices,
+	"cinnamon": AisleSpices, "oregano": AisleSpices, "thyme": AisleSpices, "rosemary": AisleSpices,
+	"nutmeg": AisleSpices, "turmeric": AisleSpices, "chili powder": AisleSpices, "clove": AisleSpices,
+	"bay leaf": AisleSpices, "cayenne": AisleSpices, "curry": AisleSpices,
+	"water": AisleBeverages, "wine": AisleBeverages, "beer": AisleBeverages, "juice": AisleBeverages,
+	"coffee": AisleBeverages, "tea": AisleBeverages,
+}
+
+func Aisle(name string) string {
+	name = " " + Singular(strings.ToLower(name)) + " "
+
+	best := ""
+	for keyword := range aisleKeywords {
+		if strings.Contains(name, " "+keyword+" ") && len(keyword) > len(best) {
+			best = keyword
+		}
+	}
+
+	if best == "" {
+		return AisleOther
+	}
+	return aisleKeywords[best]
+}
@@ -0,0 +1,232 @@
+package ingredient
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Ingredient struct {
+	Quantity float64
+	Unit     string
+	Name     string
+}
+
+const number = `\d+\s+\d+/\d+|\d+/\d+|\d+(?:\.\d+)?`
+
+var (
+	unicodeFractions = map[rune]float64{
+		'¼': 0.25, '½': 0.5, '¾': 0.75,
+		'⅓': 1.0 / 3, '⅔': 2.0 / 3,
+		'⅛': 0.125, '⅜': 0.375, '⅝': 0.625, '⅞': 0.875,
+	}
+
+	quantityRegex = regexp.MustCompile(`^(` + number + `)(?:\s*(?:-|to)\s*(` + number + `))?\s*`)
+	bulletRegex   = regexp.MustCompile(`^\s*[-*•]\s*`)
+	parensRegex   = regexp.MustCompile(`\([^)]*\)`)
+	spaceRegex    = regexp.MustCompile(`\s+`)
+)
+
+func ParseLines(text string) []Ingredient {
+	var ingredients []Ingredient
+	for _, line := range strings.Split(text, "\n") {
+		if ingredient, ok := Parse(line); ok {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	return ingredients
+}
+
+func Parse(line string) (Ingredient, bool) {
+	var ingredient Ingredient
+
+	line = expandUnicodeFractions(line)
+	line = bulletRegex.ReplaceAllString(line, "")
+	line = parensRegex.ReplaceAllString(line, "")
+	line = strings.TrimSpace(spaceRegex.ReplaceAllString(line, " "))
+	if line == "" || strings.HasSuffix(line, ":") {
+		return ingredient, false
+	}
+
+	if m := quantityRegex.FindStringSubmatch(line); m != nil {
+		ingredient.Quantity = parseNumber(m[1])
+		if m[2] != "" {
+			ingredient.Quantity = parseNumber(m[2])
+		}
+		line = line[len(m[0]):]
+	} else if lower := strings.ToLower(line); strings.HasPrefix(lower, "a ") || strings.HasPrefix(lower, "an ") {
+		ingredient.Quantity = 1
+		line = line[strings.Index(line, " ")+1:]
+	}
+
+	words := strings.Fields(line)
+	if len(words) > 1 {
+		if unit, ok := normalizeUnit(words[0]); ok {
+			ingredient.Unit = unit
+			words = words[1:]
+		}
+	}
+	if len(words) > 1 && strings.EqualFold(words[0], "of") {
+		words = words[1:]
+	}
+
+	name := strings.ToLower(strings.Join(words, " "))
+	if i := strings.Index(name, ","); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSuffix(strings.TrimSpace(name), " to taste")
+	name = strings.TrimSpace(strings.Trim(name, ".;"))
+	if name == "" {
+		return ingredient, false
+	}
+
+	ingredient.Name = name
+	return ingredient, true
+}
+
+func (i Ingredient) Key() string {
+	return i.Unit + "|" + Singular(i.Name)
+}
+
+func (i Ingredient) Scale(factor float64) Ingredient {
+	i.Quantity = i.Quantity * factor
+	return i
+}
+
+func (i Ingredient) DisplayName() string {
+	if i.Unit != "" {
+		return i.Name
+	}
+	if i.Quantity > 1 {
+		return Plural(i.Name)
+	}
+	return Singular(i.Name)
+}
+
+func (i Ingredient) DisplayUnit() string {
+	if i.Quantity > 1 {
+		if plural, ok := pluralUnits[i.Unit]; ok {
+			return plural
+		}
+	}
+	return i.Unit
+}
+
+func (i Ingredient) String() string {
+	parts := make([]string, 0, 3)
+	if i.Quantity > 0 {
+		parts = append(parts, FormatQuantity(i.Quantity))
+	}
+	if i.Unit != "" {
+		parts = append(parts, i.DisplayUnit())
+	}
+	parts = append(parts, i.DisplayName())
+	return strings.Join(parts, " ")
+}
+
+func Aggregate(ingredients []Ingredient) []Ingredient {
+	var merged []Ingredient
+	index := make(map[string]int)
+
+	for _, ingredient := range ingredients {
+		key := ingredient.Key()
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += ingredient.Quantity
+			continue
+		}
+
+		index[key] = len(merged)
+		merged = append(merged, ingredient)
+	}
+
+	return merged
+}
+
+func FormatQuantity(q float64) string {
+	whole := math.Floor(q)
+	frac := q - whole
+
+	for _, f := range commonFractions {
+		if math.Abs(frac-f.value) < 0.02 {
+			if whole == 0 {
+				return f.text
+			}
+			return fmt.Sprintf("%v %s", whole, f.text)
+		}
+	}
+
+	if frac < 0.02 {
+		return strconv.FormatFloat(whole, 'f', -1, 64)
+	}
+	if frac > 0.98 {
+		return strconv.FormatFloat(whole+1, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(math.Round(q*100)/100, 'f', -1, 64)
+}
+
+var commonFractions = []struct {
+	value float64
+	text  string
+}{
+	{0.125, "1/8"},
+	{0.25, "1/4"},
+	{1.0 / 3, "1/3"},
+	{0.5, "1/2"},
+	{2.0 / 3, "2/3"},
+	{0.75, "3/4"},
+}
+
+func parseNumber(s string) float64 {
+	var total float64
+	for _, field := range strings.Fields(s) {
+		if parts := strings.SplitN(field, "/", 2); len(parts) == 2 {
+			num, err1 := strconv.ParseFloat(parts[0], 64)
+			den, err2 := strconv.ParseFloat(parts[1], 64)
+			if err1 == nil && err2 == nil && den != 0 {
+				total += num / den
+			}
+			continue
+		}
+
+		if n, err := strconv.ParseFloat(field, 64); err == nil {
+			total += n
+		}
+	}
+	return total
+}
+
+func expandUnicodeFractions(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if v, ok := unicodeFractions[r]; ok {
+			if i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+				b.WriteByte(' ')
+			}
+			b.WriteString(fractionText(v))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func fractionText(v float64) string {
+	for _, den := range []int{2, 3, 4, 8} {
+		num := v * float64(den)
+		if math.Abs(num-math.Round(num)) < 0.001 {
+			return fmt.Sprintf("%d/%d", int(math.Round(num)), den)
+		}
+	}
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+func Matches(name, available string) bool {
+	name = " " + Singular(strings.ToLower(strings.TrimSpace(name))) + " "
+	available = Singular(strings.ToLower(strings.TrimSpace(available)))
+	if available == "" {
+		return false
+	}
+	return strings.Contains(name, " "+available+" ")
+}
@@ -0,0 +1,121 @@
+package ingredient
+
+import (
+	"strings"
+)
+
+var units = map[string]string{
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"tablespoon": "tbsp", "tablespoons": "tbsp", "tbsp": "tbsp", "tbs": "tbsp", "tbl": "tbsp",
+	"teaspoon": "tsp", "teaspoons": "tsp", "tsp": "tsp",
+	"ounce": "oz", "ounces": "oz", "oz": "oz",
+	"pound": "lb", "pounds": "lb", "lb": "lb", "lbs": "lb",
+	"gram": "g", "grams": "g", "g": "g",
+	"kilogram": "kg", "kilograms": "kg", "kg": "kg",
+	"milliliter": "ml", "milliliters": "ml", "ml": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l", "l": "l",
+	"clove": "clove", "cloves": "clove",
+	"can": "can", "cans": "can",
+	"pinch": "pinch", "pinches": "pinch",
+	"dash": "dash", "dashes": "dash",
+	"slice": "slice", "slices": "slice",
+	"stick": "stick", "sticks": "stick",
+	"bunch": "bunch", "bunches": "bunch",
+	"package": "package", "packages": "package", "pkg": "package",
+	"head": "head", "heads": "head",
+	"sprig": "sprig", "sprigs": "sprig",
+}
+
+var pluralUnits = map[string]string{
+	"cup":     "cups",
+	"clove":   "cloves",
+	"can":     "cans",
+	"pinch":   "pinches",
+	"dash":    "dashes",
+	"slice":   "slices",
+	"stick":   "sticks",
+	"bunch":   "bunches",
+	"package": "packages",
+	"head":    "heads",
+	"sprig":   "sprigs",
+}
+
+var uncountable = map[string]bool{
+	"asparagus":  true,
+	"couscous":   true,
+	"hummus":     true,
+	"molasses":   true,
+	"swiss":      true,
+	"citrus":     true,
+	"lemongrass": true,
+	"series":     true,
+}
+
+var keepsVes = map[string]bool{
+	"olives":  true,
+	"chives":  true,
+	"cloves":  true,
+	"endives": true,
+}
+
+var takesEs = map[string]bool{
+	"tomato": true,
+	"potato": true,
+	"mango":  true,
+}
+
+func normalizeUnit(word string) (string, bool) {
+	switch word {
+	case "T", "Tbsp", "Tbs":
+		return "tbsp", true
+	case "t":
+		return "tsp", true
+	}
+
+	unit, ok := units[strings.TrimSuffix(strings.ToLower(word), ".")]
+	return unit, ok
+}
+
+func Singular(name string) string {
+	prefix, last := splitLastWord(name)
+
+	switch {
+	case uncountable[last] || strings.HasSuffix(last, "ss") || len(last) <= 3:
+	case strings.HasSuffix(last, "ies"):
+		last = strings.TrimSuffix(last, "ies") + "y"
+	case strings.HasSuffix(last, "oes"), strings.HasSuffix(last, "ches"),
+		strings.HasSuffix(last, "shes"), strings.HasSuffix(last, "xes"):
+		last = strings.TrimSuffix(last, "es")
+	case strings.HasSuffix(last, "ves") && !keepsVes[last]:
+		last = strings.TrimSuffix(last, "ves") + "f"
+	case strings.HasSuffix(last, "s"):
+		last = strings.TrimSuffix(last, "s")
+	}
+
+	return prefix + last
+}
+
+func Plural(name string) string {
+	name = Singular(name)
+	prefix, last := splitLastWord(name)
+
+	switch {
+	case uncountable[last]:
+	case len(last) > 1 && strings.HasSuffix(last, "y") && !strings.ContainsAny(last[len(last)-2:len(last)-1], "aeiou"):
+		last = strings.TrimSuffix(last, "y") + "ies"
+	case strings.HasSuffix(last, "f") && last != "beef":
+		last = strings.TrimSuffix(last, "f") + "ves"
+	case takesEs[last], strings.HasSuffix(last, "ch"),
+		strings.HasSuffix(last, "sh"), strings.HasSuffix(last, "x"), strings.HasSuffix(last, "s"):
+		last += "es"
+	default:
+		last += "s"
+	}
+
+	return prefix + last
+}
+
+func splitLastWord(name string) (string, string) {
+	i := strings.LastIndex(name, " ")
+	return name[:i+1], name[i+1:]
+}
@@ -1,101 +1,157 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"net/http"
-
-	"github.com/gorilla/csrf"
-	"github.com/gorilla/mux"
-	"github.com/mpanelo/gocookit/controllers"
-	"github.com/mpanelo/gocookit/middleware"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mpanelo/gocookit/app"
+	appcontext "github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/metrics"
 	"github.com/mpanelo/gocookit/models"
 	"github.com/mpanelo/gocookit/rand"
 )
 
+var errUsage = errors.New("usage: gocookit [flags] serve|migrate|user|recipe|images|db|config")
+
 func main() {
-	isProdFlag := flag.Bool("prod", false, "Provide this flag in production to ensure that a .config file is provided before the application is started")
+	configFlags := RegisterConfigFlags(flag.CommandLine)
 	flag.Parse()
 
-	cfg := LoadConfig(*isProdFlag)
+	command, args := "serve", []string{}
+	if flag.NArg() > 0 {
+		command, args = flag.Arg(0), flag.Args()[1:]
+	}
+
+	cfg, err := LoadConfig(configFlags)
+	if command == "config" {
+		exitOnError(runConfig(cfg, err, args))
+		return
+	}
+	exitOnError(err)
+
+	slog.SetDefault(newLogger(cfg))
+
 	dbCfg := cfg.Database
 
 	services, err := models.NewServices(
 		models.WithGorm(dbCfg.ConnectionInfo()),
-		models.WithLogMode(!cfg.IsProd()),
+		models.WithQueryTimeout(time.Duration(cfg.QueryTimeout)*time.Second),
+		models.WithLogger(appcontext.Logger, !cfg.IsProd()),
 		models.WithUser(cfg.HMACKey, cfg.Pepper),
 		models.WithRecipe(),
 		models.WithImage(),
+		models.WithCollection(),
+		models.WithWorkspace(cfg.HMACKey),
+		models.WithMealPlan(),
+		models.WithShoppingList(),
+		models.WithPantry(),
+		models.WithNutrition(),
+		models.WithCookLog(),
+		models.WithComment(),
+		models.WithFavorite(),
+		models.WithRecommendation(),
+		models.WithQueryTimings(metrics.ObserveQuery),
 	)
 	must(err)
 
-	defer services.Close()
-	services.AutoMigrate()
-
-	router := mux.NewRouter()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "serve":
+		err = serve(ctx, cfg, services)
+	case "migrate":
+		err = runMigrate(ctx, services, args)
+	case "user":
+		err = runUser(ctx, services, args)
+	case "recipe":
+		err = runRecipe(ctx, services, args)
+	case "images":
+		err = runImages(ctx, services, args)
+	case "db":
+		err = runDB(ctx, services, args)
+	default:
+		err = errUsage
+	}
 
-	staticCT := controllers.NewStatic()
-	usersCT := controllers.NewUsers(services.User)
-	recipesCT := controllers.NewRecipes(services.Recipe, services.Image, router)
+	if closeErr := services.Close(); closeErr != nil {
+		slog.Error("closing services failed", "error", closeErr)
+	}
+	exitOnError(err)
+}
 
-	router.Handle("/", staticCT.Home)
+func newLogger(cfg Config) *slog.Logger {
+	level := slog.LevelInfo
+	if !cfg.IsProd() {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
 
-	assetsHandler := http.StripPrefix("/assets/", http.FileServer(http.Dir("./assets")))
-	router.PathPrefix("/assets/").Handler(assetsHandler)
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-	imagesHandler := http.StripPrefix("/images/", http.FileServer(http.Dir("./images/")))
-	router.PathPrefix("/images/").Handler(imagesHandler)
+func serve(ctx context.Context, cfg Config, services *models.Services) error {
+	if cfg.AutoMigrate {
+		if err := services.MigrateUp(ctx); err != nil {
+			return err
+		}
+	}
 
-	setUsersRoutes(router, usersCT)
-	setRecipesRoutes(router, recipesCT)
+	csrfKey, err := rand.Bytes(32)
+	if err != nil {
+		return err
+	}
 
-	b, err := rand.Bytes(32)
-	must(err)
+	handler, err := app.NewHandler(services, app.Options{
+		CSRFKey: csrfKey,
+		Secure:  cfg.IsProd() || cfg.TLSEnabled(),
+		Logger:  slog.Default(),
 
-	csrfMw := csrf.Protect(b, csrf.Secure(cfg.IsProd()))
+		ReloadTemplates: cfg.ReloadTemplates,
+	})
+	if err != nil {
+		return err
+	}
 
-	userMw := middleware.User{UserService: services.User}
+	go rebuildRecommendations(ctx, services.Recommendation, time.Duration(cfg.RecommendationsInterval)*time.Minute)
 
-	portStr := fmt.Sprintf(":%d", cfg.Port)
-	fmt.Printf("Starting gocookit on %s...\n", portStr)
-	http.ListenAndServe(portStr, csrfMw(userMw.Apply(router)))
+	return runServer(ctx, cfg, handler)
 }
 
-func setUsersRoutes(router *mux.Router, usersCT *controllers.Users) {
-	router.Handle("/signup", usersCT.SignUpView).Methods(http.MethodGet)
-	router.Handle("/signin", usersCT.SignInView).Methods(http.MethodGet)
-	router.HandleFunc("/users", usersCT.SignUp).Methods(http.MethodPost)
-	router.HandleFunc("/signin", usersCT.SignIn).Methods(http.MethodPost)
-}
+func rebuildRecommendations(ctx context.Context, rcs models.RecommendationService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
 
-func setRecipesRoutes(router *mux.Router, recipesCT *controllers.Recipes) {
-	requireUserMw := middleware.RequireUser{}
-	router.
-		Handle("/recipes", requireUserMw.ApplyFn(recipesCT.Index)).
-		Methods(http.MethodGet)
-	router.
-		Handle("/recipes", requireUserMw.ApplyFn(recipesCT.Create)).
-		Methods(http.MethodPost)
-	router.
-		Handle("/recipes/new", requireUserMw.Apply(recipesCT.NewView)).
-		Methods(http.MethodGet)
-	router.
-		Handle("/recipes/{id:[0-9]+}", requireUserMw.ApplyFn(recipesCT.Show)).
-		Methods(http.MethodGet).
-		Name(controllers.RouteRecipeShow)
-	router.
-		Handle("/recipes/{id:[0-9]+}/edit", requireUserMw.ApplyFn(recipesCT.Edit)).
-		Methods(http.MethodGet).
-		Name(controllers.RouteRecipeEdit)
-	router.
-		Handle("/recipes/{id:[0-9]+}", requireUserMw.ApplyFn(recipesCT.Update)).
-		Methods(http.MethodPost)
-	router.
-		Handle("/recipes/{id:[0-9]+}/images", requireUserMw.ApplyFn(recipesCT.ImageUpload)).
-		Methods(http.MethodPost)
-	router.
-		Handle("/recipes/{id:[0-9]+}/images/{filename}/delete", requireUserMw.ApplyFn(recipesCT.ImageDelete)).
-		Methods(http.MethodPost)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := rcs.Rebuild(ctx); err != nil {
+			slog.Error("recommendation rebuild failed", "error", err)
+		} else {
+			slog.Info("recommendations rebuilt", "duration_ms", float64(time.Since(start).Microseconds())/1000)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 func must(err error) {
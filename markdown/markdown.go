@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"bytes"
+	"html/template"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var (
+	renderer = goldmark.New(
+		goldmark.WithExtensions(extension.Linkify, extension.Strikethrough),
+		goldmark.WithRendererOptions(html.WithHardWraps()),
+	)
+
+	policy = newPolicy()
+)
+
+func Render(source string) template.HTML {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		return template.HTML(template.HTMLEscapeString(source))
+	}
+
+	return template.HTML(policy.SanitizeBytes(buf.Bytes()))
+}
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+type Cache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]template.HTML
+	order   []string
+}
+
+func NewCache(max int) *Cache {
+	return &Cache{
+		max:     max,
+		entries: make(map[string]template.HTML, max),
+	}
+}
+
+func (c *Cache) Render(key, source string) template.HTML {
+	c.mu.Lock()
+	html, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return html
+	}
+
+	html = Render(source)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		if len(c.order) >= c.max {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = html
+	return html
+}
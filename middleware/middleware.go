@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/gorilla/mux"
 	"github.com/mpanelo/gocookit/context"
 	"github.com/mpanelo/gocookit/models"
+	"github.com/mpanelo/gocookit/policy"
 )
 
 type User struct {
@@ -29,8 +32,8 @@ func (u *User) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
 			next(rw, r)
 			return
 		}
-		user, err := u.ByRemember(cookie.Value)
-		if err != nil {
+		user, err := u.ByRemember(r.Context(), cookie.Value)
+		if err != nil || user.Disabled {
 			next(rw, r)
 			return
 		}
@@ -61,3 +64,52 @@ func (ru *RequireUser) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
 		next(rw, r)
 	}
 }
+
+type Recipe struct {
+	models.RecipeService
+	Policy *policy.Policy
+	Action policy.Action
+}
+
+func (rm *Recipe) Apply(next http.Handler) http.HandlerFunc {
+	return rm.ApplyFn(next.ServeHTTP)
+}
+
+func (rm *Recipe) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		recipeID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(rw, "Invalid recipe ID", http.StatusNotFound)
+			return
+		}
+
+		recipe, err := rm.ByID(r.Context(), uint(recipeID))
+		if err != nil {
+			if err == models.ErrNotFound {
+				http.Error(rw, "Recipe not found", http.StatusNotFound)
+				return
+			}
+
+			context.Logger(r.Context()).Error("recipe middleware failed", "error", err)
+			http.Error(rw, "Something went wrong when trying to find recipe", http.StatusInternalServerError)
+			return
+		}
+
+		allowed, err := rm.Policy.Can(r.Context(), context.User(r.Context()), rm.Action, recipe)
+		if err != nil {
+			context.Logger(r.Context()).Error("recipe middleware failed", "error", err)
+			http.Error(rw, "Something went wrong when trying to authorize request", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(rw, "Recipe not found", http.StatusNotFound)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithRecipe(ctx, recipe)
+		r = r.WithContext(ctx)
+
+		next(rw, r)
+	}
+}
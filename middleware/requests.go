@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/rand"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type RequestLogger struct {
+	Logger *slog.Logger
+}
+
+func (rl *RequestLogger) Apply(next http.Handler) http.HandlerFunc {
+	return rl.ApplyFn(next.ServeHTTP)
+}
+
+func (rl *RequestLogger) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			var err error
+			id, err = rand.RequestID()
+			if err != nil {
+				rl.Logger.Error("failed to generate request ID", "error", err)
+			}
+		}
+		rw.Header().Set(RequestIDHeader, id)
+
+		info := &context.RequestInfo{ID: id}
+		logger := rl.Logger.With("request_id", id)
+
+		ctx := r.Context()
+		ctx = context.WithRequestInfo(ctx, info)
+		ctx = context.WithLogger(ctx, logger)
+		r = r.WithContext(ctx)
+
+		sw := &statusWriter{ResponseWriter: rw, status: http.StatusOK}
+		next(sw, r)
+
+		route := info.Route
+		if route == "" {
+			route = r.URL.Path
+		}
+
+		logger.Info("request",
+			"method", r.Method,
+			"route", route,
+			"status", sw.status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"user_id", info.UserID,
+		)
+	}
+}
+
+func TrackRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if info := context.RequestInfoFrom(r.Context()); info != nil {
+			if route := mux.CurrentRoute(r); route != nil {
+				if name := route.GetName(); name != "" {
+					info.Route = name
+				} else if tpl, err := route.GetPathTemplate(); err == nil {
+					info.Route = tpl
+				}
+			}
+			if user := context.User(r.Context()); user != nil {
+				info.UserID = user.ID
+			}
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+var errMigrateUsage = errors.New("usage: gocookit migrate up|down [steps]|status")
+
+func runMigrate(ctx context.Context, services *models.Services, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	switch args[0] {
+	case "up":
+		if err := services.MigrateUp(ctx); err != nil {
+			return err
+		}
+		fmt.Println("Migrations are up to date")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return errMigrateUsage
+			}
+			steps = n
+		}
+		rolledBack, err := services.MigrateDown(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", rolledBack)
+	case "status":
+		statuses, err := services.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied() {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-40s %s\n", status.Version, status.Description, state)
+		}
+	default:
+		return errMigrateUsage
+	}
+
+	return nil
+}
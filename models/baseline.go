@@ -0,0 +1,218 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type v1User struct {
+	gorm.Model
+	Name         string `gorm:"not null"`
+	Email        string `gorm:"not null;uniqueIndex"`
+	PasswordHash string `gorm:"not null"`
+	RememberHash string `gorm:"not null"`
+}
+
+func (v1User) TableName() string { return "users" }
+
+type v1Recipe struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;index"`
+	WorkspaceID  *uint  `gorm:"index"`
+	Title        string `gorm:"not null"`
+	Description  string
+	Ingredients  string
+	Instructions string
+	Servings     int
+	Allergens    uint  `gorm:"not null;default:0"`
+	Diets        uint  `gorm:"not null;default:0"`
+	ForkedFromID *uint `gorm:"index"`
+}
+
+func (v1Recipe) TableName() string { return "recipes" }
+
+type v1Collection struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	Title       string `gorm:"not null"`
+	Description string
+}
+
+func (v1Collection) TableName() string { return "collections" }
+
+type v1CollectionRecipe struct {
+	CollectionID uint `gorm:"primaryKey"`
+	RecipeID     uint `gorm:"primaryKey;index"`
+	Position     int  `gorm:"not null"`
+}
+
+func (v1CollectionRecipe) TableName() string { return "collection_recipes" }
+
+type v1Workspace struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index"`
+	Name   string `gorm:"not null"`
+}
+
+func (v1Workspace) TableName() string { return "workspaces" }
+
+type v1Membership struct {
+	WorkspaceID uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"primaryKey;index"`
+	Role        string `gorm:"not null"`
+	User        v1User
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (v1Membership) TableName() string { return "memberships" }
+
+type v1Invitation struct {
+	gorm.Model
+	WorkspaceID uint      `gorm:"not null;index"`
+	CreatedByID uint      `gorm:"not null"`
+	Role        string    `gorm:"not null"`
+	TokenHash   string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt   time.Time `gorm:"not null"`
+}
+
+func (v1Invitation) TableName() string { return "invitations" }
+
+type v1MealPlanEntry struct {
+	gorm.Model
+	UserID   uint      `gorm:"not null;index"`
+	RecipeID uint      `gorm:"not null;index"`
+	Date     time.Time `gorm:"type:date;not null;index"`
+	Slot     string    `gorm:"not null"`
+	Servings int
+	Recipe   v1Recipe
+}
+
+func (v1MealPlanEntry) TableName() string { return "meal_plan_entries" }
+
+type v1ShoppingList struct {
+	gorm.Model
+	UserID uint                 `gorm:"not null;index"`
+	Title  string               `gorm:"not null"`
+	Items  []v1ShoppingListItem `gorm:"foreignKey:ShoppingListID"`
+}
+
+func (v1ShoppingList) TableName() string { return "shopping_lists" }
+
+type v1ShoppingListItem struct {
+	gorm.Model
+	ShoppingListID uint   `gorm:"not null;index"`
+	Name           string `gorm:"not null"`
+	Quantity       float64
+	Unit           string
+	Aisle          string
+	Checked        bool
+}
+
+func (v1ShoppingListItem) TableName() string { return "shopping_list_items" }
+
+type v1PantryItem struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index"`
+	Name      string `gorm:"not null"`
+	Quantity  float64
+	Unit      string
+	ExpiresAt *time.Time `gorm:"type:date"`
+}
+
+func (v1PantryItem) TableName() string { return "pantry_items" }
+
+type v1NutritionMatch struct {
+	RecipeID   uint   `gorm:"primaryKey"`
+	Ingredient string `gorm:"primaryKey"`
+	FoodID     string `gorm:"not null"`
+}
+
+func (v1NutritionMatch) TableName() string { return "nutrition_matches" }
+
+type v1CookLog struct {
+	gorm.Model
+	UserID   uint      `gorm:"not null;index"`
+	RecipeID uint      `gorm:"not null;index"`
+	CookedOn time.Time `gorm:"type:date;not null"`
+	Rating   int       `gorm:"not null"`
+	Notes    string
+	Photo    string
+	User     v1User
+}
+
+func (v1CookLog) TableName() string { return "cook_logs" }
+
+type v1Comment struct {
+	gorm.Model
+	RecipeID uint  `gorm:"not null;index"`
+	UserID   uint  `gorm:"not null;index"`
+	ParentID *uint `gorm:"index"`
+	Body     string
+	Hidden   bool `gorm:"not null;default:false"`
+	User     v1User
+}
+
+func (v1Comment) TableName() string { return "comments" }
+
+type v1Favorite struct {
+	UserID    uint `gorm:"primaryKey"`
+	RecipeID  uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+func (v1Favorite) TableName() string { return "favorites" }
+
+type v1RecentView struct {
+	UserID   uint      `gorm:"primaryKey"`
+	RecipeID uint      `gorm:"primaryKey"`
+	ViewedAt time.Time `gorm:"not null;index"`
+}
+
+func (v1RecentView) TableName() string { return "recent_views" }
+
+type v1RecipeSimilarity struct {
+	RecipeID  uint    `gorm:"primaryKey"`
+	SimilarID uint    `gorm:"primaryKey"`
+	Score     float64 `gorm:"not null"`
+}
+
+func (v1RecipeSimilarity) TableName() string { return "recipe_similarities" }
+
+type v1RecipeCoView struct {
+	RecipeID uint `gorm:"primaryKey"`
+	OtherID  uint `gorm:"primaryKey"`
+	Count    int  `gorm:"not null;default:0"`
+}
+
+func (v1RecipeCoView) TableName() string { return "recipe_co_views" }
+
+var baselineTables = []interface{}{
+	&v1User{}, &v1Recipe{}, &v1Collection{}, &v1CollectionRecipe{}, &v1Workspace{}, &v1Membership{}, &v1Invitation{},
+	&v1MealPlanEntry{}, &v1ShoppingList{}, &v1ShoppingListItem{}, &v1PantryItem{}, &v1NutritionMatch{}, &v1CookLog{},
+	&v1Comment{}, &v1Favorite{}, &v1RecentView{}, &v1RecipeSimilarity{}, &v1RecipeCoView{},
+}
+
+type v2User struct {
+	Disabled bool `gorm:"not null;default:false"`
+}
+
+func (v2User) TableName() string { return "users" }
+
+type v3Invitation struct {
+	AcceptedAt   *time.Time
+	AcceptedByID *uint
+}
+
+func (v3Invitation) TableName() string { return "invitations" }
+
+type v4Recipe struct {
+	ID              uint
+	Ingredients     string
+	Allergens       uint `gorm:"not null;default:0"`
+	Diets           uint `gorm:"not null;default:0"`
+	DietaryAnalyzed bool `gorm:"not null;default:false"`
+}
+
+func (v4Recipe) TableName() string { return "recipes" }
@@ -0,0 +1,263 @@
+package models
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type Collection struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	Title       string `gorm:"not null"`
+	Description string
+	Recipes     []Recipe `gorm:"-"`
+}
+
+type CollectionRecipe struct {
+	CollectionID uint `gorm:"primaryKey"`
+	RecipeID     uint `gorm:"primaryKey;index"`
+	Position     int  `gorm:"not null"`
+}
+
+type CollectionService interface {
+	CollectionDB
+}
+
+type collectionService struct {
+	CollectionDB
+}
+
+func NewCollectionService(db *gorm.DB) CollectionService {
+	return &collectionService{&collectionValidator{&collectionGorm{db}}}
+}
+
+type CollectionDB interface {
+	ByID(context.Context, uint) (*Collection, error)
+	ByUserID(context.Context, uint) ([]Collection, error)
+	ByRecipeID(context.Context, uint) ([]Collection, error)
+	Recipes(context.Context, uint) ([]Recipe, error)
+	Create(context.Context, *Collection) error
+	Update(context.Context, *Collection) error
+	Delete(context.Context, uint) error
+	AddRecipe(ctx context.Context, collectionID, recipeID uint) error
+	RemoveRecipe(ctx context.Context, collectionID, recipeID uint) error
+	MoveRecipe(ctx context.Context, collectionID, recipeID uint, position int) error
+}
+
+type collectionValidator struct {
+	CollectionDB
+}
+
+func (cv *collectionValidator) Create(ctx context.Context, collection *Collection) error {
+	err := runCollectionValidatorFuncs(collection,
+		collectionUserIDRequired,
+		collectionTitleRequired)
+	if err != nil {
+		return err
+	}
+
+	return cv.CollectionDB.Create(ctx, collection)
+}
+
+func (cv *collectionValidator) Update(ctx context.Context, collection *Collection) error {
+	err := runCollectionValidatorFuncs(collection,
+		collectionUserIDRequired,
+		collectionTitleRequired)
+	if err != nil {
+		return err
+	}
+
+	return cv.CollectionDB.Update(ctx, collection)
+}
+
+func (cv *collectionValidator) Delete(ctx context.Context, id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+	return cv.CollectionDB.Delete(ctx, id)
+}
+
+func collectionUserIDRequired(collection *Collection) error {
+	if collection.UserID <= 0 {
+		return ErrCollectionUserIDRequired
+	}
+	return nil
+}
+
+func collectionTitleRequired(collection *Collection) error {
+	if collection.Title == "" {
+		return ErrCollectionTitleRequired
+	}
+	return nil
+}
+
+type collectionGorm struct {
+	db *gorm.DB
+}
+
+func (cg *collectionGorm) ByID(ctx context.Context, id uint) (*Collection, error) {
+	var collection Collection
+	tx := cg.db.WithContext(ctx).Where("id = ?", id)
+
+	if err := first(tx, &collection); err != nil {
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+func (cg *collectionGorm) ByUserID(ctx context.Context, userID uint) ([]Collection, error) {
+	var collections []Collection
+	result := cg.db.WithContext(ctx).Where("user_id = ?", userID).Order("title").Find(&collections)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return collections, nil
+}
+
+func (cg *collectionGorm) ByRecipeID(ctx context.Context, recipeID uint) ([]Collection, error) {
+	var collections []Collection
+	result := cg.db.WithContext(ctx).
+		Joins("JOIN collection_recipes ON collection_recipes.collection_id = collections.id").
+		Where("collection_recipes.recipe_id = ?", recipeID).
+		Order("collections.title").
+		Find(&collections)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return collections, nil
+}
+
+func (cg *collectionGorm) Recipes(ctx context.Context, collectionID uint) ([]Recipe, error) {
+	var recipes []Recipe
+	result := cg.db.WithContext(ctx).
+		Joins("JOIN collection_recipes ON collection_recipes.recipe_id = recipes.id").
+		Where("collection_recipes.collection_id = ?", collectionID).
+		Order("collection_recipes.position").
+		Find(&recipes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return recipes, nil
+}
+
+func (cg *collectionGorm) Create(ctx context.Context, collection *Collection) error {
+	result := cg.db.WithContext(ctx).Create(collection)
+	return result.Error
+}
+
+func (cg *collectionGorm) Update(ctx context.Context, collection *Collection) error {
+	result := cg.db.WithContext(ctx).Save(collection)
+	return result.Error
+}
+
+func (cg *collectionGorm) Delete(ctx context.Context, id uint) error {
+	return cg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("collection_id = ?", id).Delete(&CollectionRecipe{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&Collection{}, id).Error
+	})
+}
+
+func (cg *collectionGorm) AddRecipe(ctx context.Context, collectionID, recipeID uint) error {
+	return cg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		err := tx.Model(&CollectionRecipe{}).
+			Where("collection_id = ? AND recipe_id = ?", collectionID, recipeID).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		var count int64
+		err = tx.Model(&CollectionRecipe{}).
+			Where("collection_id = ?", collectionID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&CollectionRecipe{
+			CollectionID: collectionID,
+			RecipeID:     recipeID,
+			Position:     int(count),
+		}).Error
+	})
+}
+
+func (cg *collectionGorm) RemoveRecipe(ctx context.Context, collectionID, recipeID uint) error {
+	return cg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("collection_id = ? AND recipe_id = ?", collectionID, recipeID).
+			Delete(&CollectionRecipe{}).Error
+		if err != nil {
+			return err
+		}
+		return renumberCollection(tx, collectionID, nil)
+	})
+}
+
+func (cg *collectionGorm) MoveRecipe(ctx context.Context, collectionID, recipeID uint, position int) error {
+	return cg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entry CollectionRecipe
+		err := first(tx.Where("collection_id = ? AND recipe_id = ?", collectionID, recipeID), &entry)
+		if err != nil {
+			return err
+		}
+
+		entry.Position = position
+		return renumberCollection(tx, collectionID, &entry)
+	})
+}
+
+func renumberCollection(tx *gorm.DB, collectionID uint, moved *CollectionRecipe) error {
+	var entries []CollectionRecipe
+	err := tx.Where("collection_id = ?", collectionID).Order("position").Find(&entries).Error
+	if err != nil {
+		return err
+	}
+
+	ordered := make([]CollectionRecipe, 0, len(entries))
+	for _, entry := range entries {
+		if moved == nil || entry.RecipeID != moved.RecipeID {
+			ordered = append(ordered, entry)
+		}
+	}
+
+	if moved != nil {
+		pos := moved.Position
+		if pos < 0 {
+			pos = 0
+		}
+		if pos > len(ordered) {
+			pos = len(ordered)
+		}
+		ordered = append(ordered[:pos], append([]CollectionRecipe{*moved}, ordered[pos:]...)...)
+	}
+
+	for i, entry := range ordered {
+		err := tx.Model(&CollectionRecipe{}).
+			Where("collection_id = ? AND recipe_id = ?", entry.CollectionID, entry.RecipeID).
+			Update("position", i).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type collectionValidatorFunc func(*Collection) error
+
+func runCollectionValidatorFuncs(collection *Collection, funcs ...collectionValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const commentBodyMaxLen = 5000
+
+type Comment struct {
+	gorm.Model
+	RecipeID uint  `gorm:"not null;index"`
+	UserID   uint  `gorm:"not null;index"`
+	ParentID *uint `gorm:"index"`
+	Body     string
+	Hidden   bool `gorm:"not null;default:false"`
+	User     User
+	Replies  []Comment `gorm:"-"`
+}
+
+func Thread(comments []Comment) []Comment {
+	children := make(map[uint][]Comment)
+	var roots []Comment
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			roots = append(roots, comment)
+			continue
+		}
+		children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+	}
+
+	var attach func([]Comment) []Comment
+	attach = func(level []Comment) []Comment {
+		for i := range level {
+			level[i].Replies = attach(children[level[i].ID])
+		}
+		return level
+	}
+
+	return attach(roots)
+}
+
+type CommentService interface {
+	CommentDB
+}
+
+type commentService struct {
+	CommentDB
+}
+
+func NewCommentService(db *gorm.DB) CommentService {
+	return &commentService{&commentValidator{&commentGorm{db}}}
+}
+
+type CommentDB interface {
+	ByID(context.Context, uint) (*Comment, error)
+	ByRecipeID(context.Context, uint) ([]Comment, error)
+	Create(context.Context, *Comment) error
+	Update(context.Context, *Comment) error
+	Delete(context.Context, uint) error
+}
+
+type commentValidator struct {
+	CommentDB
+}
+
+func (cv *commentValidator) Create(ctx context.Context, comment *Comment) error {
+	err := runCommentValidatorFuncs(comment,
+		commentUserIDRequired,
+		commentRecipeIDRequired,
+		commentNormalizeBody,
+		commentBodyRequired,
+		commentBodyLength,
+		cv.commentParentValid(ctx))
+	if err != nil {
+		return err
+	}
+
+	return cv.CommentDB.Create(ctx, comment)
+}
+
+func (cv *commentValidator) Update(ctx context.Context, comment *Comment) error {
+	err := runCommentValidatorFuncs(comment,
+		commentUserIDRequired,
+		commentRecipeIDRequired,
+		commentNormalizeBody,
+		commentBodyRequired,
+		commentBodyLength)
+	if err != nil {
+		return err
+	}
+
+	return cv.CommentDB.Update(ctx, comment)
+}
+
+func (cv *commentValidator) Delete(ctx context.Context, id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+	return cv.CommentDB.Delete(ctx, id)
+}
+
+func commentUserIDRequired(comment *Comment) error {
+	if comment.UserID <= 0 {
+		return ErrCommentUserIDRequired
+	}
+	return nil
+}
+
+func commentRecipeIDRequired(comment *Comment) error {
+	if comment.RecipeID <= 0 {
+		return ErrCommentRecipeIDRequired
+	}
+	return nil
+}
+
+func commentNormalizeBody(comment *Comment) error {
+	comment.Body = strings.TrimSpace(comment.Body)
+	return nil
+}
+
+func commentBodyRequired(comment *Comment) error {
+	if comment.Body == "" {
+		return ErrCommentBodyRequired
+	}
+	return nil
+}
+
+func commentBodyLength(comment *Comment) error {
+	if len(comment.Body) > commentBodyMaxLen {
+		return ErrCommentBodyTooLong
+	}
+	return nil
+}
+
+func (cv *commentValidator) commentParentValid(ctx context.Context) commentValidatorFunc {
+	return func(comment *Comment) error {
+		if comment.ParentID == nil {
+			return nil
+		}
+
+		parent, err := cv.ByID(ctx, *comment.ParentID)
+		if err != nil {
+			if err == ErrNotFound {
+				return ErrCommentParentInvalid
+			}
+			return err
+		}
+
+		if parent.RecipeID != comment.RecipeID {
+			return ErrCommentParentInvalid
+		}
+		return nil
+	}
+}
+
+type commentGorm struct {
+	db *gorm.DB
+}
+
+func (cg *commentGorm) ByID(ctx context.Context, id uint) (*Comment, error) {
+	var comment Comment
+	tx := cg.db.WithContext(ctx).Where("id = ?", id)
+
+	if err := first(tx, &comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (cg *commentGorm) ByRecipeID(ctx context.Context, recipeID uint) ([]Comment, error) {
+	var comments []Comment
+	result := cg.db.WithContext(ctx).Preload("User").
+		Where("recipe_id = ?", recipeID).
+		Order("created_at").
+		Find(&comments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return comments, nil
+}
+
+func (cg *commentGorm) Create(ctx context.Context, comment *Comment) error {
+	result := cg.db.WithContext(ctx).Omit("User").Create(comment)
+	return result.Error
+}
+
+func (cg *commentGorm) Update(ctx context.Context, comment *Comment) error {
+	result := cg.db.WithContext(ctx).Omit("User").Save(comment)
+	return result.Error
+}
+
+func (cg *commentGorm) Delete(ctx context.Context, id uint) error {
+	return cg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := []uint{id}
+		for parents := ids; len(parents) > 0; {
+			var children []uint
+			err := tx.Model(&Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error
+			if err != nil {
+				return err
+			}
+			ids = append(ids, children...)
+			parents = children
+		}
+
+		return tx.Delete(&Comment{}, ids).Error
+	})
+}
+
+type commentValidatorFunc func(*Comment) error
+
+func runCommentValidatorFuncs(comment *Comment, funcs ...commentValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RatingMin = 1
+	RatingMax = 5
+
+	recentCookLogsLimit = 5
+)
+
+type CookLog struct {
+	gorm.Model
+	UserID   uint      `gorm:"not null;index"`
+	RecipeID uint      `gorm:"not null;index"`
+	CookedOn time.Time `gorm:"type:date;not null"`
+	Rating   int       `gorm:"not null"`
+	Notes    string
+	Photo    string
+	User     User
+}
+
+func (c *CookLog) PhotoImage() *Photo {
+	if c.Photo == "" {
+		return nil
+	}
+	return &Photo{CookLogID: c.ID, Filename: c.Photo}
+}
+
+type Photo struct {
+	CookLogID uint
+	Filename  string
+}
+
+func (p *Photo) Path() string {
+	u := url.URL{
+		Path: "/" + p.RelativePath(),
+	}
+
+	return u.String()
+}
+
+func (p *Photo) RelativePath() string {
+	return fmt.Sprintf("images/cooklogs/%v/%v", p.CookLogID, p.Filename)
+}
+
+type CookStats struct {
+	RecipeID      uint
+	Count         int
+	AverageRating float64
+	LastCooked    *time.Time
+}
+
+func (s CookStats) Rated() bool {
+	return s.Count > 0
+}
+
+type CookLogService interface {
+	CookLogDB
+}
+
+type cookLogService struct {
+	CookLogDB
+}
+
+func NewCookLogService(db *gorm.DB) CookLogService {
+	return &cookLogService{&cookLogValidator{&cookLogGorm{db}}}
+}
+
+type CookLogDB interface {
+	ByID(context.Context, uint) (*CookLog, error)
+	RecentByRecipeID(context.Context, uint) ([]CookLog, error)
+	WithPhotos(ctx context.Context) ([]CookLog, error)
+	Stats(ctx context.Context, recipeIDs ...uint) (map[uint]CookStats, error)
+	Create(context.Context, *CookLog) error
+	Delete(context.Context, uint) error
+}
+
+type cookLogValidator struct {
+	CookLogDB
+}
+
+func (cv *cookLogValidator) Create(ctx context.Context, cookLog *CookLog) error {
+	err := runCookLogValidatorFuncs(cookLog,
+		cookLogUserIDRequired,
+		cookLogRecipeIDRequired,
+		cookLogDateRequired,
+		cookLogRatingInRange)
+	if err != nil {
+		return err
+	}
+
+	return cv.CookLogDB.Create(ctx, cookLog)
+}
+
+func (cv *cookLogValidator) Delete(ctx context.Context, id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+	return cv.CookLogDB.Delete(ctx, id)
+}
+
+func cookLogUserIDRequired(cookLog *CookLog) error {
+	if cookLog.UserID <= 0 {
+		return ErrCookLogUserIDRequired
+	}
+	return nil
+}
+
+func cookLogRecipeIDRequired(cookLog *CookLog) error {
+	if cookLog.RecipeID <= 0 {
+		return ErrCookLogRecipeIDRequired
+	}
+	return nil
+}
+
+func cookLogDateRequired(cookLog *CookLog) error {
+	if cookLog.CookedOn.IsZero() {
+		return ErrCookLogDateRequired
+	}
+	cookLog.CookedOn = StartOfDay(cookLog.CookedOn)
+	return nil
+}
+
+func cookLogRatingInRange(cookLog *CookLog) error {
+	if cookLog.Rating < RatingMin || cookLog.Rating > RatingMax {
+		return ErrCookLogRatingInvalid
+	}
+	return nil
+}
+
+type cookLogGorm struct {
+	db *gorm.DB
+}
+
+func (cg *cookLogGorm) ByID(ctx context.Context, id uint) (*CookLog, error) {
+	var cookLog CookLog
+	tx := cg.db.WithContext(ctx).Where("id = ?", id)
+
+	if err := first(tx, &cookLog); err != nil {
+		return nil, err
+	}
+
+	return &cookLog, nil
+}
+
+func (cg *cookLogGorm) RecentByRecipeID(ctx context.Context, recipeID uint) ([]CookLog, error) {
+	var cookLogs []CookLog
+	result := cg.db.WithContext(ctx).Preload("User").
+		Where("recipe_id = ?", recipeID).
+		Order("cooked_on DESC, id DESC").
+		Limit(recentCookLogsLimit).
+		Find(&cookLogs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return cookLogs, nil
+}
+
+func (cg *cookLogGorm) WithPhotos(ctx context.Context) ([]CookLog, error) {
+	var cookLogs []CookLog
+	result := cg.db.WithContext(ctx).Where("photo <> ''").Order("recipe_id, id").Find(&cookLogs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return cookLogs, nil
+}
+
+func (cg *cookLogGorm) Stats(ctx context.Context, recipeIDs ...uint) (map[uint]CookStats, error) {
+	stats := make(map[uint]CookStats, len(recipeIDs))
+	if len(recipeIDs) == 0 {
+		return stats, nil
+	}
+
+	var rows []cookStatsRow
+	result := cg.db.WithContext(ctx).Model(&CookLog{}).
+		Select("recipe_id, count(*) AS count, avg(rating) AS average_rating, max(cooked_on) AS last_cooked").
+		Where("recipe_id IN ?", recipeIDs).
+		Group("recipe_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, row := range rows {
+		stats[row.RecipeID] = CookStats{
+			RecipeID:      row.RecipeID,
+			Count:         row.Count,
+			AverageRating: row.AverageRating,
+			LastCooked:    row.LastCooked.Time,
+		}
+	}
+	return stats, nil
+}
+
+type cookStatsRow struct {
+	RecipeID      uint
+	Count         int
+	AverageRating float64
+	LastCooked    scannedTime
+}
+
+var scannedTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	DateLayout,
+}
+
+type scannedTime struct {
+	Time *time.Time
+}
+
+func (st *scannedTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		st.Time = nil
+		return nil
+	case time.Time:
+		st.Time = &v
+		return nil
+	case []byte:
+		return st.parse(string(v))
+	case string:
+		return st.parse(v)
+	}
+	return fmt.Errorf("cannot scan %T into a time", value)
+}
+
+func (st scannedTime) Value() (driver.Value, error) {
+	if st.Time == nil {
+		return nil, nil
+	}
+	return *st.Time, nil
+}
+
+func (st *scannedTime) parse(s string) error {
+	for _, layout := range scannedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			st.Time = &t
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as a time", s)
+}
+
+func (cg *cookLogGorm) Create(ctx context.Context, cookLog *CookLog) error {
+	result := cg.db.WithContext(ctx).Omit("User").Create(cookLog)
+	return result.Error
+}
+
+func (cg *cookLogGorm) Delete(ctx context.Context, id uint) error {
+	result := cg.db.WithContext(ctx).Delete(&CookLog{}, id)
+	return result.Error
+}
+
+type cookLogValidatorFunc func(*CookLog) error
+
+func runCookLogValidatorFuncs(cookLog *CookLog, funcs ...cookLogValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(cookLog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"context"
+
+	"github.com/mpanelo/gocookit/similarity"
+	"gorm.io/gorm"
+)
+
+func (rs *recipeService) Duplicates(ctx context.Context, recipe *Recipe) ([]Recipe, error) {
+	recipes, err := rs.ByUserID(ctx, recipe.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := similarity.NewDocument(recipe.Title, recipe.Ingredients)
+
+	var duplicates []Recipe
+	for _, other := range recipes {
+		if other.ID == recipe.ID {
+			continue
+		}
+		if similarity.IsDuplicate(doc, similarity.NewDocument(other.Title, other.Ingredients)) {
+			duplicates = append(duplicates, other)
+		}
+	}
+	return duplicates, nil
+}
+
+func (rs *recipeService) DuplicateGroups(ctx context.Context, userID uint) ([][]Recipe, error) {
+	recipes, err := rs.ByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]similarity.Document, len(recipes))
+	for i, recipe := range recipes {
+		docs[i] = similarity.NewDocument(recipe.Title, recipe.Ingredients)
+	}
+
+	parent := make([]int, len(recipes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range recipes {
+		for j := i + 1; j < len(recipes); j++ {
+			if similarity.IsDuplicate(docs[i], docs[j]) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := make(map[int][]Recipe)
+	var roots []int
+	for i, recipe := range recipes {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], recipe)
+	}
+
+	var groups [][]Recipe
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups, nil
+}
+
+func (rv *recipeValidator) Merge(ctx context.Context, canonicalID uint, duplicateIDs []uint) error {
+	if canonicalID <= 0 {
+		return ErrIDInvalid
+	}
+	if len(duplicateIDs) == 0 {
+		return ErrMergeDuplicatesRequired
+	}
+	for _, id := range duplicateIDs {
+		if id <= 0 {
+			return ErrIDInvalid
+		}
+		if id == canonicalID {
+			return ErrMergeCanonicalInvalid
+		}
+	}
+
+	return rv.RecipeDB.Merge(ctx, canonicalID, duplicateIDs)
+}
+
+var mergeTables = []struct {
+	table  string
+	unique string
+}{
+	{"collection_recipes", "collection_id"},
+	{"favorites", "user_id"},
+	{"recent_views", "user_id"},
+	{"nutrition_matches", "ingredient"},
+	{"meal_plan_entries", ""},
+	{"cook_logs", ""},
+	{"comments", ""},
+}
+
+func (rg *recipeGorm) Merge(ctx context.Context, canonicalID uint, duplicateIDs []uint) error {
+	return rg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, t := range mergeTables {
+			if t.unique != "" {
+				err := tx.Exec("DELETE FROM "+t.table+" WHERE recipe_id IN ? AND "+t.unique+" IN (SELECT "+t.unique+" FROM "+t.table+" WHERE recipe_id = ?)",
+					duplicateIDs, canonicalID).Error
+				if err != nil {
+					return err
+				}
+
+				err = tx.Exec("DELETE FROM "+t.table+" WHERE recipe_id IN ? AND EXISTS (SELECT 1 FROM "+t.table+" o WHERE o."+t.unique+" = "+t.table+"."+t.unique+" AND o.recipe_id IN ? AND o.recipe_id < "+t.table+".recipe_id)",
+					duplicateIDs, duplicateIDs).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			err := tx.Exec("UPDATE "+t.table+" SET recipe_id = ? WHERE recipe_id IN ?", canonicalID, duplicateIDs).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&Recipe{}).
+			Where("forked_from_id IN ? AND id <> ?", duplicateIDs, canonicalID).
+			Update("forked_from_id", canonicalID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&Recipe{}).
+			Where("id = ? AND forked_from_id IN ?", canonicalID, duplicateIDs).
+			Update("forked_from_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&Recipe{}, duplicateIDs).Error
+	})
+}
@@ -5,20 +5,58 @@ import (
 )
 
 const (
-	ErrNotFound                 = privateError("resource not found")
-	ErrIDInvalid                = privateError("ID has an invalid value")
-	ErrUserPasswordHashRequired = privateError("password hash is required")
-	ErrUserRememberHashRequired = privateError("remember hash is required")
-	ErrUserRememberTooShort     = privateError("remember token must be at least 32 bytes long")
-	ErrRecipeUserIDRequired     = privateError("user ID is required")
-	ErrUserPasswordRequired     = publicError("password is required")
-	ErrUserEmailRequired        = publicError("email is required")
-	ErrUserNameRequired         = publicError("full name is required")
-	ErrUserPasswordTooShort     = publicError("password must be at least 8 characters long")
-	ErrUserEmailInvalid         = publicError("email provided has an invalid format")
-	ErrUserEmailTaken           = publicError("email is already taken")
-	ErrUserCredentialsInvalid   = publicError("email or password provided is invalid")
-	ErrRecipeTitleRequired      = publicError("recipe title is required")
+	ErrNotFound                    = privateError("resource not found")
+	ErrIDInvalid                   = privateError("ID has an invalid value")
+	ErrUserPasswordHashRequired    = privateError("password hash is required")
+	ErrUserRememberHashRequired    = privateError("remember hash is required")
+	ErrUserRememberTooShort        = privateError("remember token must be at least 32 bytes long")
+	ErrRecipeUserIDRequired        = privateError("user ID is required")
+	ErrCollectionUserIDRequired    = privateError("user ID is required")
+	ErrWorkspaceUserIDRequired     = privateError("user ID is required")
+	ErrInvitationTokenRequired     = privateError("invitation token is required")
+	ErrMealPlanUserIDRequired      = privateError("user ID is required")
+	ErrShoppingListUserIDRequired  = privateError("user ID is required")
+	ErrPantryUserIDRequired        = privateError("user ID is required")
+	ErrCookLogUserIDRequired       = privateError("user ID is required")
+	ErrCookLogRecipeIDRequired     = privateError("recipe ID is required")
+	ErrCommentUserIDRequired       = privateError("user ID is required")
+	ErrCommentRecipeIDRequired     = privateError("recipe ID is required")
+	ErrNutritionRecipeIDRequired   = privateError("recipe ID is required")
+	ErrMigrationStepsInvalid       = privateError("migration steps must be a positive number")
+	ErrUserPasswordRequired        = publicError("password is required")
+	ErrUserEmailRequired           = publicError("email is required")
+	ErrUserNameRequired            = publicError("full name is required")
+	ErrUserPasswordTooShort        = publicError("password must be at least 8 characters long")
+	ErrUserEmailInvalid            = publicError("email provided has an invalid format")
+	ErrUserEmailTaken              = publicError("email is already taken")
+	ErrUserCredentialsInvalid      = publicError("email or password provided is invalid")
+	ErrUserDisabled                = publicError("this account has been disabled")
+	ErrRecipeTitleRequired         = publicError("recipe title is required")
+	ErrRecipeReassignSameUser      = publicError("recipes must be reassigned to a different user")
+	ErrCollectionTitleRequired     = publicError("collection title is required")
+	ErrWorkspaceNameRequired       = publicError("workspace name is required")
+	ErrWorkspaceOwnerRequired      = publicError("workspace must keep at least one owner")
+	ErrRoleInvalid                 = publicError("role must be owner, editor or viewer")
+	ErrInvitationInvalid           = publicError("invitation link is invalid, has expired or was already used")
+	ErrInvitationRoleOwner         = publicError("invitation links cannot grant the owner role")
+	ErrMealPlanRecipeRequired      = publicError("recipe is required")
+	ErrMealPlanDateRequired        = publicError("date is required")
+	ErrMealPlanSlotInvalid         = publicError("meal must be breakfast, lunch, dinner or snack")
+	ErrServingsInvalid             = publicError("servings cannot be negative")
+	ErrShoppingListTitleRequired   = publicError("shopping list title is required")
+	ErrShoppingListEmpty           = publicError("select at least one recipe to build a shopping list")
+	ErrPantryNameRequired          = publicError("pantry item name is required")
+	ErrPantryQuantityInvalid       = publicError("quantity cannot be negative")
+	ErrCookLogDateRequired         = publicError("date cooked is required")
+	ErrCookLogRatingInvalid        = publicError("rating must be between 1 and 5 stars")
+	ErrCommentBodyRequired         = publicError("comment cannot be empty")
+	ErrCommentBodyTooLong          = publicError("comment must be at most 5000 characters long")
+	ErrCommentParentInvalid        = publicError("the comment you replied to no longer exists")
+	ErrMergeDuplicatesRequired     = publicError("select at least one duplicate to merge")
+	ErrMergeCanonicalInvalid       = publicError("the recipe to keep cannot also be merged away")
+	ErrNutritionIngredientRequired = publicError("ingredient is required")
+	ErrNutritionFoodInvalid        = publicError("selected food is not in the nutrition database")
+	ErrImageFilenameInvalid        = publicError("image file name is invalid")
 )
 
 type privateError string
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const RecentViewsLimit = 10
+
+type Favorite struct {
+	UserID    uint `gorm:"primaryKey"`
+	RecipeID  uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+type RecentView struct {
+	UserID   uint      `gorm:"primaryKey"`
+	RecipeID uint      `gorm:"primaryKey"`
+	ViewedAt time.Time `gorm:"not null;index"`
+}
+
+type FavoriteService interface {
+	FavoriteDB
+}
+
+type favoriteService struct {
+	FavoriteDB
+}
+
+func NewFavoriteService(db *gorm.DB) FavoriteService {
+	return &favoriteService{&favoriteValidator{&favoriteGorm{db}}}
+}
+
+type FavoriteDB interface {
+	IsFavorite(ctx context.Context, userID, recipeID uint) (bool, error)
+	Recipes(ctx context.Context, userID uint) ([]Recipe, error)
+	Add(ctx context.Context, userID, recipeID uint) error
+	Remove(ctx context.Context, userID, recipeID uint) error
+
+	RecordView(ctx context.Context, userID, recipeID uint) error
+	RecentlyViewed(ctx context.Context, userID uint) ([]Recipe, error)
+}
+
+type favoriteValidator struct {
+	FavoriteDB
+}
+
+func (fv *favoriteValidator) Add(ctx context.Context, userID, recipeID uint) error {
+	if userID <= 0 || recipeID <= 0 {
+		return ErrIDInvalid
+	}
+	return fv.FavoriteDB.Add(ctx, userID, recipeID)
+}
+
+func (fv *favoriteValidator) Remove(ctx context.Context, userID, recipeID uint) error {
+	if userID <= 0 || recipeID <= 0 {
+		return ErrIDInvalid
+	}
+	return fv.FavoriteDB.Remove(ctx, userID, recipeID)
+}
+
+func (fv *favoriteValidator) RecordView(ctx context.Context, userID, recipeID uint) error {
+	if userID <= 0 || recipeID <= 0 {
+		return ErrIDInvalid
+	}
+	return fv.FavoriteDB.RecordView(ctx, userID, recipeID)
+}
+
+type favoriteGorm struct {
+	db *gorm.DB
+}
+
+func (fg *favoriteGorm) IsFavorite(ctx context.Context, userID, recipeID uint) (bool, error) {
+	var count int64
+	result := fg.db.WithContext(ctx).Model(&Favorite{}).
+		Where("user_id = ? AND recipe_id = ?", userID, recipeID).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (fg *favoriteGorm) Recipes(ctx context.Context, userID uint) ([]Recipe, error) {
+	var recipes []Recipe
+	result := fg.db.WithContext(ctx).
+		Joins("JOIN favorites ON favorites.recipe_id = recipes.id").
+		Where("favorites.user_id = ?", userID).
+		Order("favorites.created_at DESC").
+		Find(&recipes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return recipes, nil
+}
+
+func (fg *favoriteGorm) Add(ctx context.Context, userID, recipeID uint) error {
+	favorite := Favorite{UserID: userID, RecipeID: recipeID}
+	result := fg.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite)
+	return result.Error
+}
+
+func (fg *favoriteGorm) Remove(ctx context.Context, userID, recipeID uint) error {
+	result := fg.db.WithContext(ctx).Where("user_id = ? AND recipe_id = ?", userID, recipeID).Delete(&Favorite{})
+	return result.Error
+}
+
+func (fg *favoriteGorm) RecordView(ctx context.Context, userID, recipeID uint) error {
+	return fg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		view := RecentView{UserID: userID, RecipeID: recipeID, ViewedAt: time.Now()}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "recipe_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"viewed_at"}),
+		}).Create(&view).Error
+		if err != nil {
+			return err
+		}
+
+		keep := tx.Model(&RecentView{}).
+			Select("recipe_id").
+			Where("user_id = ?", userID).
+			Order("viewed_at DESC").
+			Limit(RecentViewsLimit)
+		return tx.Where("user_id = ? AND recipe_id NOT IN (?)", userID, keep).Delete(&RecentView{}).Error
+	})
+}
+
+func (fg *favoriteGorm) RecentlyViewed(ctx context.Context, userID uint) ([]Recipe, error) {
+	var recipes []Recipe
+	result := fg.db.WithContext(ctx).
+		Joins("JOIN recent_views ON recent_views.recipe_id = recipes.id").
+		Where("recent_views.user_id = ?", userID).
+		Order("recent_views.viewed_at DESC").
+		Limit(RecentViewsLimit).
+		Find(&recipes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return recipes, nil
+}
@@ -1,11 +1,13 @@
 package models
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -27,57 +29,337 @@ func (i *Image) RelativePath() string {
 }
 
 type ImageService interface {
-	Create(uint, io.Reader, string) error
-	ByRecipeID(uint) ([]Image, error)
-	Delete(*Image) error
+	Create(context.Context, uint, io.Reader, string) error
+	ByRecipeID(context.Context, uint) ([]Image, error)
+	Copy(context.Context, *Image, uint) (*Image, error)
+	Delete(context.Context, *Image) error
+	RecipeIDs(context.Context) ([]uint, error)
+	DeleteRecipeDir(context.Context, uint) error
+	CreatePhoto(context.Context, *Photo, io.Reader) error
+	PhotosByCookLogID(context.Context, uint) ([]Photo, error)
+	PhotoCookLogIDs(context.Context) ([]uint, error)
+	DeletePhotoDir(context.Context, uint) error
+	CheckWritable(context.Context) error
 }
 
 func NewImageService() ImageService {
-	return &imageService{}
+	return &imageValidator{&imageService{root: "images"}}
 }
 
-type imageService struct{}
+type imageValidator struct {
+	ImageService
+}
+
+func (iv *imageValidator) Create(ctx context.Context, recipeID uint, src io.Reader, fileName string) error {
+	err := runImageValidatorFuncs(&Image{RecipeID: recipeID, Filename: fileName},
+		imageRecipeIDRequired,
+		imageFilenameValid)
+	if err != nil {
+		return err
+	}
 
-func (is *imageService) Delete(i *Image) error {
-	return os.Remove(i.RelativePath())
+	return iv.ImageService.Create(ctx, recipeID, src, fileName)
 }
 
-func (is *imageService) ByRecipeID(recipeID uint) ([]Image, error) {
-	pathPrefix := is.imageDir(recipeID)
-	imagePaths, err := filepath.Glob(pathPrefix + "/*")
+func (iv *imageValidator) ByRecipeID(ctx context.Context, recipeID uint) ([]Image, error) {
+	if recipeID <= 0 {
+		return nil, ErrIDInvalid
+	}
+	return iv.ImageService.ByRecipeID(ctx, recipeID)
+}
+
+func (iv *imageValidator) Copy(ctx context.Context, i *Image, recipeID uint) (*Image, error) {
+	err := runImageValidatorFuncs(i,
+		imageRecipeIDRequired,
+		imageFilenameValid)
 	if err != nil {
 		return nil, err
 	}
+	if recipeID <= 0 {
+		return nil, ErrIDInvalid
+	}
 
-	images := make([]Image, len(imagePaths))
-	for i := range imagePaths {
-		images[i] = Image{
-			RecipeID: recipeID,
-			Filename: strings.Replace(imagePaths[i], pathPrefix+"/", "", 1),
+	return iv.ImageService.Copy(ctx, i, recipeID)
+}
+
+func (iv *imageValidator) Delete(ctx context.Context, i *Image) error {
+	err := runImageValidatorFuncs(i,
+		imageRecipeIDRequired,
+		imageFilenameValid)
+	if err != nil {
+		return err
+	}
+
+	return iv.ImageService.Delete(ctx, i)
+}
+
+func (iv *imageValidator) DeleteRecipeDir(ctx context.Context, recipeID uint) error {
+	if recipeID <= 0 {
+		return ErrIDInvalid
+	}
+	return iv.ImageService.DeleteRecipeDir(ctx, recipeID)
+}
+
+func (iv *imageValidator) CreatePhoto(ctx context.Context, p *Photo, src io.Reader) error {
+	if p.CookLogID <= 0 {
+		return ErrIDInvalid
+	}
+	if err := imageFilenameValid(&Image{Filename: p.Filename}); err != nil {
+		return err
+	}
+	return iv.ImageService.CreatePhoto(ctx, p, src)
+}
+
+func (iv *imageValidator) PhotosByCookLogID(ctx context.Context, cookLogID uint) ([]Photo, error) {
+	if cookLogID <= 0 {
+		return nil, ErrIDInvalid
+	}
+	return iv.ImageService.PhotosByCookLogID(ctx, cookLogID)
+}
+
+func (iv *imageValidator) DeletePhotoDir(ctx context.Context, cookLogID uint) error {
+	if cookLogID <= 0 {
+		return ErrIDInvalid
+	}
+	return iv.ImageService.DeletePhotoDir(ctx, cookLogID)
+}
+
+type imageValidatorFunc func(*Image) error
+
+func imageRecipeIDRequired(i *Image) error {
+	if i.RecipeID <= 0 {
+		return ErrIDInvalid
+	}
+	return nil
+}
+
+func imageFilenameValid(i *Image) error {
+	if i.Filename == "" || strings.HasPrefix(i.Filename, ".") || strings.ContainsAny(i.Filename, `/\`) {
+		return ErrImageFilenameInvalid
+	}
+	return nil
+}
+
+func runImageValidatorFuncs(i *Image, funcs ...imageValidatorFunc) error {
+	for _, fn := range funcs {
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type imageService struct {
+	root string
+}
+
+func (is *imageService) Delete(ctx context.Context, i *Image) error {
+	return os.Remove(is.path(i))
+}
+
+func (is *imageService) Copy(ctx context.Context, i *Image, recipeID uint) (*Image, error) {
+	src, err := os.Open(is.path(i))
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	dir, err := is.mkImageDir(recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := writeTempFile(dir, &contextReader{ctx: ctx, r: src})
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	for n := 1; ; n++ {
+		filename := numberedFilename(i.Filename, n)
+		err := os.Link(tmp, filepath.Join(dir, filename))
+		if os.IsExist(err) {
+			continue
 		}
+		if err != nil {
+			return nil, err
+		}
+		return &Image{RecipeID: recipeID, Filename: filename}, nil
+	}
+}
+
+func (is *imageService) ByRecipeID(ctx context.Context, recipeID uint) ([]Image, error) {
+	names, err := listFiles(is.imageDir(recipeID))
+	if err != nil {
+		return nil, err
 	}
 
+	images := make([]Image, len(names))
+	for i, name := range names {
+		images[i] = Image{RecipeID: recipeID, Filename: name}
+	}
 	return images, nil
 }
 
-func (is *imageService) Create(recipeID uint, src io.Reader, fileName string) error {
+func (is *imageService) Create(ctx context.Context, recipeID uint, src io.Reader, fileName string) error {
 	dir, err := is.mkImageDir(recipeID)
 	if err != nil {
 		return err
 	}
 
-	dst, err := os.Create(filepath.Join(dir, fileName))
+	return writeFileAtomic(filepath.Join(dir, fileName), &contextReader{ctx: ctx, r: src})
+}
+
+func writeFileAtomic(path string, src io.Reader) error {
+	tmp, err := writeTempFile(filepath.Dir(path), src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func writeTempFile(dir string, src io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(dir, ".upload-*")
 	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+func numberedFilename(filename string, n int) string {
+	if n <= 1 {
+		return filename
+	}
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filename, ext), n, ext)
+}
+
+func (is *imageService) RecipeIDs(ctx context.Context) ([]uint, error) {
+	return listIDDirs(filepath.Join(is.root, "recipes"))
+}
+
+func (is *imageService) DeleteRecipeDir(ctx context.Context, recipeID uint) error {
+	return os.RemoveAll(is.imageDir(recipeID))
+}
+
+func (is *imageService) CreatePhoto(ctx context.Context, p *Photo, src io.Reader) error {
+	dir := is.photoDir(p.CookLogID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
-	defer dst.Close()
 
-	_, err = io.Copy(dst, src)
+	return writeFileAtomic(filepath.Join(dir, p.Filename), &contextReader{ctx: ctx, r: src})
+}
+
+func (is *imageService) PhotosByCookLogID(ctx context.Context, cookLogID uint) ([]Photo, error) {
+	names, err := listFiles(is.photoDir(cookLogID))
 	if err != nil {
+		return nil, err
+	}
+
+	photos := make([]Photo, len(names))
+	for i, name := range names {
+		photos[i] = Photo{CookLogID: cookLogID, Filename: name}
+	}
+	return photos, nil
+}
+
+func (is *imageService) PhotoCookLogIDs(ctx context.Context) ([]uint, error) {
+	return listIDDirs(filepath.Join(is.root, "cooklogs"))
+}
+
+func (is *imageService) DeletePhotoDir(ctx context.Context, cookLogID uint) error {
+	return os.RemoveAll(is.photoDir(cookLogID))
+}
+
+func (is *imageService) CheckWritable(ctx context.Context) error {
+	dir := filepath.Join(is.root, "recipes")
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
-	return nil
+	f, err := os.CreateTemp(dir, ".writable-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return err
+	}
+	return os.Remove(name)
+}
+
+func listFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+func listIDDirs(dir string) ([]uint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ids []uint
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		id, err := strconv.ParseUint(entry.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
 }
 
 func (is *imageService) mkImageDir(recipeID uint) (string, error) {
@@ -89,5 +371,13 @@ func (is *imageService) mkImageDir(recipeID uint) (string, error) {
 }
 
 func (is *imageService) imageDir(recipeID uint) string {
-	return filepath.Join("images", "recipes", fmt.Sprintf("%d", recipeID))
+	return filepath.Join(is.root, "recipes", fmt.Sprintf("%d", recipeID))
+}
+
+func (is *imageService) photoDir(cookLogID uint) string {
+	return filepath.Join(is.root, "cooklogs", fmt.Sprintf("%d", cookLogID))
+}
+
+func (is *imageService) path(i *Image) string {
+	return filepath.Join(is.imageDir(i.RecipeID), i.Filename)
 }
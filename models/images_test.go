@@ -0,0 +1,213 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImageCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		recipeID uint
+		filename string
+		want     error
+	}{
+		{"valid", 1, "bread.jpg", nil},
+		{"recipe required", 0, "bread.jpg", ErrIDInvalid},
+		{"filename required", 1, "", ErrImageFilenameInvalid},
+		{"dot", 1, ".", ErrImageFilenameInvalid},
+		{"parent", 1, "..", ErrImageFilenameInvalid},
+		{"hidden", 1, ".upload-bread.jpg", ErrImageFilenameInvalid},
+		{"traversal", 1, "../2/bread.jpg", ErrImageFilenameInvalid},
+		{"backslash", 1, `..\bread.jpg`, ErrImageFilenameInvalid},
+	}
+
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := s.Image.Create(ctx, tt.recipeID, strings.NewReader("image"), tt.filename)
+				if err != tt.want {
+					t.Fatalf("Create() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+
+		images, err := s.Image.ByRecipeID(ctx, 1)
+		if err != nil {
+			t.Fatalf("ByRecipeID: %v", err)
+		}
+		want := []Image{{RecipeID: 1, Filename: "bread.jpg"}}
+		if !reflect.DeepEqual(images, want) {
+			t.Errorf("ByRecipeID() = %v, want %v", images, want)
+		}
+	})
+}
+
+func TestImageCreateKeepsOriginalOnFailure(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		if err := s.Image.Create(ctx, 1, strings.NewReader("original"), "bread.jpg"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := s.Image.Create(cancelled, 1, strings.NewReader("replacement"), "bread.jpg"); err != context.Canceled {
+			t.Fatalf("Create() with a cancelled context error = %v, want %v", err, context.Canceled)
+		}
+
+		images, err := s.Image.ByRecipeID(ctx, 1)
+		if err != nil {
+			t.Fatalf("ByRecipeID: %v", err)
+		}
+		want := []Image{{RecipeID: 1, Filename: "bread.jpg"}}
+		if !reflect.DeepEqual(images, want) {
+			t.Errorf("ByRecipeID() after an aborted upload = %v, want %v", images, want)
+		}
+	})
+}
+
+func TestImageCopyRenamesOnCollision(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		for _, id := range []uint{1, 2} {
+			if err := s.Image.Create(ctx, id, strings.NewReader("image"), "bread.jpg"); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		for _, want := range []string{"bread-2.jpg", "bread-3.jpg"} {
+			image, err := s.Image.Copy(ctx, &Image{RecipeID: 2, Filename: "bread.jpg"}, 1)
+			if err != nil {
+				t.Fatalf("Copy: %v", err)
+			}
+			if image.Filename != want {
+				t.Errorf("Copy() = %s, want %s", image.Filename, want)
+			}
+		}
+
+		images, err := s.Image.ByRecipeID(ctx, 1)
+		if err != nil {
+			t.Fatalf("ByRecipeID: %v", err)
+		}
+		want := []Image{{1, "bread-2.jpg"}, {1, "bread-3.jpg"}, {1, "bread.jpg"}}
+		if !reflect.DeepEqual(images, want) {
+			t.Errorf("ByRecipeID() = %v, want %v", images, want)
+		}
+	})
+}
+
+func TestImageCopyAndDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		if err := s.Image.Create(ctx, 1, strings.NewReader("image"), "bread.jpg"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		copies := []struct {
+			name     string
+			image    Image
+			recipeID uint
+			want     error
+		}{
+			{"source recipe required", Image{Filename: "bread.jpg"}, 2, ErrIDInvalid},
+			{"target recipe required", Image{RecipeID: 1, Filename: "bread.jpg"}, 0, ErrIDInvalid},
+			{"traversal", Image{RecipeID: 1, Filename: "../1/bread.jpg"}, 2, ErrImageFilenameInvalid},
+			{"valid", Image{RecipeID: 1, Filename: "bread.jpg"}, 2, nil},
+		}
+		for _, tt := range copies {
+			t.Run("copy "+tt.name, func(t *testing.T) {
+				if _, err := s.Image.Copy(ctx, &tt.image, tt.recipeID); err != tt.want {
+					t.Fatalf("Copy() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+
+		deletes := []struct {
+			name  string
+			image Image
+			want  error
+		}{
+			{"recipe required", Image{Filename: "bread.jpg"}, ErrIDInvalid},
+			{"filename required", Image{RecipeID: 2}, ErrImageFilenameInvalid},
+			{"traversal", Image{RecipeID: 2, Filename: "../1/bread.jpg"}, ErrImageFilenameInvalid},
+			{"valid", Image{RecipeID: 2, Filename: "bread.jpg"}, nil},
+		}
+		for _, tt := range deletes {
+			t.Run("delete "+tt.name, func(t *testing.T) {
+				if err := s.Image.Delete(ctx, &tt.image); err != tt.want {
+					t.Fatalf("Delete() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+
+		for _, id := range []uint{1, 2} {
+			images, err := s.Image.ByRecipeID(ctx, id)
+			if err != nil {
+				t.Fatalf("ByRecipeID(%d): %v", id, err)
+			}
+			if want := 2 - int(id); len(images) != want {
+				t.Errorf("ByRecipeID(%d) returned %d images, want %d", id, len(images), want)
+			}
+		}
+
+		if err := s.Image.DeleteRecipeDir(ctx, 0); err != ErrIDInvalid {
+			t.Fatalf("DeleteRecipeDir(0) error = %v, want %v", err, ErrIDInvalid)
+		}
+		if _, err := s.Image.ByRecipeID(ctx, 0); err != ErrIDInvalid {
+			t.Fatalf("ByRecipeID(0) error = %v, want %v", err, ErrIDInvalid)
+		}
+	})
+}
+
+func TestPhotos(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		creates := []struct {
+			name  string
+			photo Photo
+			want  error
+		}{
+			{"cook log required", Photo{Filename: "dinner.jpg"}, ErrIDInvalid},
+			{"filename required", Photo{CookLogID: 1}, ErrImageFilenameInvalid},
+			{"traversal", Photo{CookLogID: 1, Filename: "../2/dinner.jpg"}, ErrImageFilenameInvalid},
+			{"valid", Photo{CookLogID: 1, Filename: "dinner.jpg"}, nil},
+			{"same name on another cook log", Photo{CookLogID: 2, Filename: "dinner.jpg"}, nil},
+		}
+		for _, tt := range creates {
+			t.Run("create "+tt.name, func(t *testing.T) {
+				if err := s.Image.CreatePhoto(ctx, &tt.photo, strings.NewReader("photo")); err != tt.want {
+					t.Fatalf("CreatePhoto() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+
+		if images, err := s.Image.ByRecipeID(ctx, 1); err != nil || len(images) != 0 {
+			t.Fatalf("ByRecipeID(1) = %v, %v, want no images", images, err)
+		}
+		if err := s.Image.DeletePhotoDir(ctx, 0); err != ErrIDInvalid {
+			t.Fatalf("DeletePhotoDir(0) error = %v, want %v", err, ErrIDInvalid)
+		}
+		if err := s.Image.DeletePhotoDir(ctx, 1); err != nil {
+			t.Fatalf("DeletePhotoDir: %v", err)
+		}
+
+		ids, err := s.Image.PhotoCookLogIDs(ctx)
+		if err != nil {
+			t.Fatalf("PhotoCookLogIDs: %v", err)
+		}
+		if want := []uint{2}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("PhotoCookLogIDs() = %v, want %v", ids, want)
+		}
+		photos, err := s.Image.PhotosByCookLogID(ctx, 2)
+		if err != nil {
+			t.Fatalf("PhotosByCookLogID: %v", err)
+		}
+		if want := []Photo{{CookLogID: 2, Filename: "dinner.jpg"}}; !reflect.DeepEqual(photos, want) {
+			t.Errorf("PhotosByCookLogID(2) = %v, want %v", photos, want)
+		}
+	})
+}
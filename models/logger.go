@@ -0,0 +1,77 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const slowQueryThreshold = 200 * time.Millisecond
+
+type LoggerFunc func(context.Context) *slog.Logger
+
+func WithLogger(loggerFor LoggerFunc, debug bool) ServicesConfig {
+	return func(s *Services) error {
+		level := logger.Warn
+		if debug {
+			level = logger.Info
+		}
+		s.db.Logger = &gormLogger{loggerFor: loggerFor, level: level}
+		return nil
+	}
+}
+
+type gormLogger struct {
+	loggerFor LoggerFunc
+	level     logger.LogLevel
+}
+
+func (gl *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &gormLogger{loggerFor: gl.loggerFor, level: level}
+}
+
+func (gl *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if gl.level >= logger.Info {
+		gl.loggerFor(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (gl *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if gl.level >= logger.Warn {
+		gl.loggerFor(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (gl *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if gl.level >= logger.Error {
+		gl.loggerFor(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (gl *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if gl.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && gl.level >= logger.Error:
+		sql, rows := fc()
+		gl.loggerFor(ctx).Error("query failed", "error", err, "sql", sql, "rows", rows, "duration_ms", durationMS(elapsed))
+	case elapsed > slowQueryThreshold && gl.level >= logger.Warn:
+		sql, rows := fc()
+		gl.loggerFor(ctx).Warn("slow query", "sql", sql, "rows", rows, "duration_ms", durationMS(elapsed))
+	case gl.level >= logger.Info:
+		sql, rows := fc()
+		gl.loggerFor(ctx).Debug("query", "sql", sql, "rows", rows, "duration_ms", durationMS(elapsed))
+	}
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
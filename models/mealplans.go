@@ -0,0 +1,316 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MealSlot string
+
+const (
+	MealSlotBreakfast MealSlot = "breakfast"
+	MealSlotLunch     MealSlot = "lunch"
+	MealSlotDinner    MealSlot = "dinner"
+	MealSlotSnack     MealSlot = "snack"
+
+	DateLayout = "2006-01-02"
+)
+
+var MealSlots = []MealSlot{MealSlotBreakfast, MealSlotLunch, MealSlotDinner, MealSlotSnack}
+
+func (s MealSlot) Valid() bool {
+	for _, slot := range MealSlots {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
+
+type MealPlanEntry struct {
+	gorm.Model
+	UserID   uint      `gorm:"not null;index"`
+	RecipeID uint      `gorm:"not null;index"`
+	Date     time.Time `gorm:"type:date;not null;index"`
+	Slot     MealSlot  `gorm:"not null"`
+	Servings int
+	Recipe   Recipe
+}
+
+func (e *MealPlanEntry) EffectiveServings() int {
+	if e.Servings > 0 {
+		return e.Servings
+	}
+	return e.Recipe.Servings
+}
+
+type mealPlanKey struct {
+	userID   uint
+	recipeID uint
+	date     string
+	slot     MealSlot
+}
+
+func (e *MealPlanEntry) key() mealPlanKey {
+	return mealPlanKey{
+		userID:   e.UserID,
+		recipeID: e.RecipeID,
+		date:     e.Date.Format(DateLayout),
+		slot:     e.Slot,
+	}
+}
+
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+type MealPlanService interface {
+	MealPlanDB
+	RepeatWeek(ctx context.Context, userID uint, from, to time.Time) error
+}
+
+type mealPlanService struct {
+	MealPlanDB
+}
+
+func NewMealPlanService(db *gorm.DB) MealPlanService {
+	return &mealPlanService{&mealPlanValidator{&mealPlanGorm{db}}}
+}
+
+func (ms *mealPlanService) RepeatWeek(ctx context.Context, userID uint, from, to time.Time) error {
+	from = StartOfWeek(from)
+	to = StartOfWeek(to)
+
+	entries, err := ms.ByUserIDBetween(ctx, userID, from, from.AddDate(0, 0, 7))
+	if err != nil {
+		return err
+	}
+
+	days := int(to.Sub(from).Hours() / 24)
+	copies := make([]MealPlanEntry, 0, len(entries))
+	for _, entry := range entries {
+		copies = append(copies, MealPlanEntry{
+			UserID:   entry.UserID,
+			RecipeID: entry.RecipeID,
+			Date:     entry.Date.AddDate(0, 0, days),
+			Slot:     entry.Slot,
+			Servings: entry.Servings,
+		})
+	}
+
+	return ms.CreateMissing(ctx, copies)
+}
+
+type MealPlanDB interface {
+	ByID(context.Context, uint) (*MealPlanEntry, error)
+	ByUserIDBetween(ctx context.Context, userID uint, from, to time.Time) ([]MealPlanEntry, error)
+	Create(context.Context, *MealPlanEntry) error
+	CreateMissing(context.Context, []MealPlanEntry) error
+	Update(context.Context, *MealPlanEntry) error
+	Delete(context.Context, uint) error
+}
+
+type mealPlanValidator struct {
+	MealPlanDB
+}
+
+func (mv *mealPlanValidator) Create(ctx context.Context, entry *MealPlanEntry) error {
+	err := runMealPlanValidatorFuncs(entry,
+		mealPlanUserIDRequired,
+		mealPlanRecipeIDRequired,
+		mealPlanNormalizeDate,
+		mealPlanDateRequired,
+		mealPlanSlotValid,
+		mealPlanServingsNonNegative)
+	if err != nil {
+		return err
+	}
+
+	return mv.MealPlanDB.Create(ctx, entry)
+}
+
+func (mv *mealPlanValidator) CreateMissing(ctx context.Context, entries []MealPlanEntry) error {
+	for i := range entries {
+		err := runMealPlanValidatorFuncs(&entries[i],
+			mealPlanUserIDRequired,
+			mealPlanRecipeIDRequired,
+			mealPlanNormalizeDate,
+			mealPlanDateRequired,
+			mealPlanSlotValid,
+			mealPlanServingsNonNegative)
+		if err != nil {
+			return err
+		}
+	}
+
+	return mv.MealPlanDB.CreateMissing(ctx, entries)
+}
+
+func (mv *mealPlanValidator) Update(ctx context.Context, entry *MealPlanEntry) error {
+	err := runMealPlanValidatorFuncs(entry,
+		mealPlanUserIDRequired,
+		mealPlanRecipeIDRequired,
+		mealPlanNormalizeDate,
+		mealPlanDateRequired,
+		mealPlanSlotValid,
+		mealPlanServingsNonNegative)
+	if err != nil {
+		return err
+	}
+
+	return mv.MealPlanDB.Update(ctx, entry)
+}
+
+func (mv *mealPlanValidator) Delete(ctx context.Context, id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+	return mv.MealPlanDB.Delete(ctx, id)
+}
+
+func mealPlanUserIDRequired(entry *MealPlanEntry) error {
+	if entry.UserID <= 0 {
+		return ErrMealPlanUserIDRequired
+	}
+	return nil
+}
+
+func mealPlanRecipeIDRequired(entry *MealPlanEntry) error {
+	if entry.RecipeID <= 0 {
+		return ErrMealPlanRecipeRequired
+	}
+	return nil
+}
+
+func mealPlanNormalizeDate(entry *MealPlanEntry) error {
+	if !entry.Date.IsZero() {
+		entry.Date = StartOfDay(entry.Date)
+	}
+	return nil
+}
+
+func mealPlanDateRequired(entry *MealPlanEntry) error {
+	if entry.Date.IsZero() {
+		return ErrMealPlanDateRequired
+	}
+	return nil
+}
+
+func mealPlanSlotValid(entry *MealPlanEntry) error {
+	if !entry.Slot.Valid() {
+		return ErrMealPlanSlotInvalid
+	}
+	return nil
+}
+
+func mealPlanServingsNonNegative(entry *MealPlanEntry) error {
+	if entry.Servings < 0 {
+		return ErrServingsInvalid
+	}
+	return nil
+}
+
+type mealPlanGorm struct {
+	db *gorm.DB
+}
+
+func (mg *mealPlanGorm) ByID(ctx context.Context, id uint) (*MealPlanEntry, error) {
+	var entry MealPlanEntry
+	tx := mg.db.WithContext(ctx).Preload("Recipe").Where("id = ?", id)
+
+	if err := first(tx, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (mg *mealPlanGorm) ByUserIDBetween(ctx context.Context, userID uint, from, to time.Time) ([]MealPlanEntry, error) {
+	var entries []MealPlanEntry
+	result := mg.db.WithContext(ctx).Preload("Recipe").
+		Where("user_id = ? AND date >= ? AND date < ?", userID, from, to).
+		Order("date, id").
+		Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+func (mg *mealPlanGorm) Create(ctx context.Context, entry *MealPlanEntry) error {
+	result := mg.db.WithContext(ctx).Omit("Recipe").Create(entry)
+	return result.Error
+}
+
+func (mg *mealPlanGorm) CreateMissing(ctx context.Context, entries []MealPlanEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uint, 0, 1)
+	from, to := entries[0].Date, entries[0].Date
+	for _, entry := range entries {
+		userIDs = append(userIDs, entry.UserID)
+		if entry.Date.Before(from) {
+			from = entry.Date
+		}
+		if entry.Date.After(to) {
+			to = entry.Date
+		}
+	}
+
+	return mg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []MealPlanEntry
+		err := tx.Where("user_id IN ? AND date >= ? AND date < ?", userIDs, from, to.AddDate(0, 0, 1)).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[mealPlanKey]bool, len(existing)+len(entries))
+		for _, entry := range existing {
+			seen[entry.key()] = true
+		}
+
+		for i := range entries {
+			if seen[entries[i].key()] {
+				continue
+			}
+			seen[entries[i].key()] = true
+
+			if err := tx.Omit("Recipe").Create(&entries[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (mg *mealPlanGorm) Update(ctx context.Context, entry *MealPlanEntry) error {
+	result := mg.db.WithContext(ctx).Omit("Recipe").Save(entry)
+	return result.Error
+}
+
+func (mg *mealPlanGorm) Delete(ctx context.Context, id uint) error {
+	result := mg.db.WithContext(ctx).Delete(&MealPlanEntry{}, id)
+	return result.Error
+}
+
+type mealPlanValidatorFunc func(*MealPlanEntry) error
+
+func runMealPlanValidatorFuncs(entry *MealPlanEntry, funcs ...mealPlanValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
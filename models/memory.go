@@ -0,0 +1,369 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+func NewMemoryUserService(hmacKey, pepper string) UserService {
+	return newUserService(&userMemory{users: make(map[uint]User)}, hmacKey, pepper)
+}
+
+type userMemory struct {
+	mu     sync.RWMutex
+	users  map[uint]User
+	nextID uint
+}
+
+func (um *userMemory) All(ctx context.Context) ([]User, error) {
+	um.mu.RLock()
+	defer um.mu.RUnlock()
+
+	users := make([]User, 0, len(um.users))
+	for _, user := range um.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (um *userMemory) ByID(ctx context.Context, id uint) (*User, error) {
+	return um.find(func(user *User) bool { return user.ID == id })
+}
+
+func (um *userMemory) ByEmail(ctx context.Context, email string) (*User, error) {
+	return um.find(func(user *User) bool { return user.Email == email })
+}
+
+func (um *userMemory) ByRemember(ctx context.Context, rememberHash string) (*User, error) {
+	return um.find(func(user *User) bool { return user.RememberHash == rememberHash })
+}
+
+func (um *userMemory) Create(ctx context.Context, user *User) error {
+	um.mu.Lock()
+	defer um.mu.Unlock()
+
+	um.nextID++
+	now := time.Now()
+	user.ID = um.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	um.users[user.ID] = *user
+	return nil
+}
+
+func (um *userMemory) Update(ctx context.Context, user *User) error {
+	um.mu.Lock()
+	defer um.mu.Unlock()
+
+	if _, ok := um.users[user.ID]; !ok {
+		return ErrNotFound
+	}
+	user.UpdatedAt = time.Now()
+	um.users[user.ID] = *user
+	return nil
+}
+
+func (um *userMemory) find(match func(*User) bool) (*User, error) {
+	um.mu.RLock()
+	defer um.mu.RUnlock()
+
+	for _, user := range um.users {
+		if match(&user) {
+			found := user
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func NewMemoryRecipeService() RecipeService {
+	return &recipeService{&recipeValidator{&recipeMemory{recipes: make(map[uint]Recipe)}}}
+}
+
+type recipeMemory struct {
+	mu      sync.RWMutex
+	recipes map[uint]Recipe
+	nextID  uint
+}
+
+func (rm *recipeMemory) ByID(ctx context.Context, id uint) (*Recipe, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	recipe, ok := rm.recipes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &recipe, nil
+}
+
+func (rm *recipeMemory) ByUserID(ctx context.Context, userID uint) ([]Recipe, error) {
+	recipes := rm.filter(func(recipe *Recipe) bool { return recipe.UserID == userID })
+	sort.SliceStable(recipes, func(i, j int) bool { return recipes[i].UpdatedAt.After(recipes[j].UpdatedAt) })
+	return recipes, nil
+}
+
+func (rm *recipeMemory) ByForkedFromID(ctx context.Context, recipeID uint) ([]Recipe, error) {
+	return rm.filter(func(recipe *Recipe) bool {
+		return recipe.ForkedFromID != nil && *recipe.ForkedFromID == recipeID
+	}), nil
+}
+
+func (rm *recipeMemory) ByWorkspaceID(ctx context.Context, workspaceID uint) ([]Recipe, error) {
+	return rm.filter(func(recipe *Recipe) bool {
+		return recipe.WorkspaceID != nil && *recipe.WorkspaceID == workspaceID
+	}), nil
+}
+
+func (rm *recipeMemory) Create(ctx context.Context, recipe *Recipe) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.nextID++
+	now := time.Now()
+	recipe.ID = rm.nextID
+	recipe.CreatedAt = now
+	recipe.UpdatedAt = now
+	rm.recipes[recipe.ID] = *recipe
+	return nil
+}
+
+func (rm *recipeMemory) Update(ctx context.Context, recipe *Recipe) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, ok := rm.recipes[recipe.ID]; !ok {
+		return ErrNotFound
+	}
+	recipe.UpdatedAt = time.Now()
+	rm.recipes[recipe.ID] = *recipe
+	return nil
+}
+
+func (rm *recipeMemory) Delete(ctx context.Context, id uint) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	delete(rm.recipes, id)
+	return nil
+}
+
+func (rm *recipeMemory) Merge(ctx context.Context, canonicalID uint, duplicateIDs []uint) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, ok := rm.recipes[canonicalID]; !ok {
+		return ErrNotFound
+	}
+
+	duplicates := make(map[uint]bool, len(duplicateIDs))
+	for _, id := range duplicateIDs {
+		duplicates[id] = true
+		delete(rm.recipes, id)
+	}
+
+	for id, recipe := range rm.recipes {
+		if recipe.ForkedFromID != nil && duplicates[*recipe.ForkedFromID] && id != canonicalID {
+			forkedFromID := canonicalID
+			recipe.ForkedFromID = &forkedFromID
+			rm.recipes[id] = recipe
+		}
+	}
+	return nil
+}
+
+func (rm *recipeMemory) IDs(ctx context.Context) ([]uint, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	ids := make([]uint, 0, len(rm.recipes))
+	for id := range rm.recipes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (rm *recipeMemory) Reassign(ctx context.Context, fromUserID, toUserID uint) (int64, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	var n int64
+	for id, recipe := range rm.recipes {
+		if recipe.UserID == fromUserID {
+			recipe.UserID = toUserID
+			rm.recipes[id] = recipe
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (rm *recipeMemory) filter(match func(*Recipe) bool) []Recipe {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	var recipes []Recipe
+	for _, recipe := range rm.recipes {
+		if match(&recipe) {
+			recipes = append(recipes, recipe)
+		}
+	}
+	sort.SliceStable(recipes, func(i, j int) bool { return recipes[i].ID < recipes[j].ID })
+	return recipes
+}
+
+func NewMemoryImageService() ImageService {
+	return &imageValidator{&imageMemory{
+		images: make(map[uint]map[string][]byte),
+		photos: make(map[uint]map[string][]byte),
+	}}
+}
+
+type imageMemory struct {
+	mu     sync.RWMutex
+	images map[uint]map[string][]byte
+	photos map[uint]map[string][]byte
+}
+
+func (im *imageMemory) Create(ctx context.Context, recipeID uint, src io.Reader, fileName string) error {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, &contextReader{ctx: ctx, r: src}); err != nil {
+		return err
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if im.images[recipeID] == nil {
+		im.images[recipeID] = make(map[string][]byte)
+	}
+	im.images[recipeID][fileName] = buf.Bytes()
+	return nil
+}
+
+func (im *imageMemory) ByRecipeID(ctx context.Context, recipeID uint) ([]Image, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	images := make([]Image, 0, len(im.images[recipeID]))
+	for name := range im.images[recipeID] {
+		images = append(images, Image{RecipeID: recipeID, Filename: name})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Filename < images[j].Filename })
+	return images, nil
+}
+
+func (im *imageMemory) Copy(ctx context.Context, i *Image, recipeID uint) (*Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	data, ok := im.images[i.RecipeID][i.Filename]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if im.images[recipeID] == nil {
+		im.images[recipeID] = make(map[string][]byte)
+	}
+
+	for n := 1; ; n++ {
+		filename := numberedFilename(i.Filename, n)
+		if _, ok := im.images[recipeID][filename]; ok {
+			continue
+		}
+		im.images[recipeID][filename] = data
+		return &Image{RecipeID: recipeID, Filename: filename}, nil
+	}
+}
+
+func (im *imageMemory) Delete(ctx context.Context, i *Image) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if _, ok := im.images[i.RecipeID][i.Filename]; !ok {
+		return ErrNotFound
+	}
+	delete(im.images[i.RecipeID], i.Filename)
+	return nil
+}
+
+func (im *imageMemory) RecipeIDs(ctx context.Context) ([]uint, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	ids := make([]uint, 0, len(im.images))
+	for id := range im.images {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (im *imageMemory) DeleteRecipeDir(ctx context.Context, recipeID uint) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	delete(im.images, recipeID)
+	return nil
+}
+
+func (im *imageMemory) CreatePhoto(ctx context.Context, p *Photo, src io.Reader) error {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, &contextReader{ctx: ctx, r: src}); err != nil {
+		return err
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if im.photos[p.CookLogID] == nil {
+		im.photos[p.CookLogID] = make(map[string][]byte)
+	}
+	im.photos[p.CookLogID][p.Filename] = buf.Bytes()
+	return nil
+}
+
+func (im *imageMemory) PhotosByCookLogID(ctx context.Context, cookLogID uint) ([]Photo, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	photos := make([]Photo, 0, len(im.photos[cookLogID]))
+	for name := range im.photos[cookLogID] {
+		photos = append(photos, Photo{CookLogID: cookLogID, Filename: name})
+	}
+	sort.Slice(photos, func(i, j int) bool { return photos[i].Filename < photos[j].Filename })
+	return photos, nil
+}
+
+func (im *imageMemory) PhotoCookLogIDs(ctx context.Context) ([]uint, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	ids := make([]uint, 0, len(im.photos))
+	for id := range im.photos {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (im *imageMemory) DeletePhotoDir(ctx context.Context, cookLogID uint) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	delete(im.photos, cookLogID)
+	return nil
+}
+
+func (im *imageMemory) CheckWritable(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/mpanelo/gocookit/dietary"
+	"github.com/mpanelo/gocookit/ingredient"
+	"gorm.io/gorm"
+)
+
+const (
+	migrationLockKey = 7243011
+	migrationTimeout = 30 * time.Minute
+)
+
+type Migration struct {
+	Version     uint
+	Description string
+	Up          func(*gorm.DB) error
+	Down        func(*gorm.DB) error
+}
+
+type SchemaMigration struct {
+	Version     uint `gorm:"primaryKey;autoIncrement:false"`
+	Description string
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+type MigrationStatus struct {
+	Version     uint
+	Description string
+	AppliedAt   *time.Time
+}
+
+func (ms MigrationStatus) Applied() bool {
+	return ms.AppliedAt != nil
+}
+
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create baseline schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(baselineTables...)
+		},
+		Down: func(tx *gorm.DB) error {
+			tables := make([]interface{}, len(baselineTables))
+			for i, table := range baselineTables {
+				tables[len(tables)-1-i] = table
+			}
+			return tx.Migrator().DropTable(tables...)
+		},
+	},
+	{
+		Version:     2,
+		Description: "add users.disabled",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&v2User{}, "Disabled")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&v2User{}, "Disabled")
+		},
+	},
+	{
+		Version:     3,
+		Description: "add invitations.accepted_at and accepted_by_id",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&v3Invitation{}, "AcceptedAt"); err != nil {
+				return err
+			}
+			return tx.Migrator().AddColumn(&v3Invitation{}, "AcceptedByID")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&v3Invitation{}, "AcceptedByID"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&v3Invitation{}, "AcceptedAt")
+		},
+	},
+	{
+		Version:     4,
+		Description: "add recipes.dietary_analyzed and backfill dietary flags",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&v4Recipe{}, "DietaryAnalyzed"); err != nil {
+				return err
+			}
+			return backfillDietaryFlags(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&v4Recipe{}, "DietaryAnalyzed")
+		},
+	},
+}
+
+func backfillDietaryFlags(tx *gorm.DB) error {
+	var recipes []v4Recipe
+	return tx.Select("id", "ingredients").FindInBatches(&recipes, 500, func(batch *gorm.DB, _ int) error {
+		for _, recipe := range recipes {
+			allergens, diets, analyzed := dietary.Analyze(ingredient.ParseLines(recipe.Ingredients))
+			err := tx.Model(&v4Recipe{}).Where("id = ?", recipe.ID).Updates(map[string]interface{}{
+				"allergens":        uint(allergens),
+				"diets":            uint(diets),
+				"dietary_analyzed": analyzed,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func (s *Services) MigrateUp(ctx context.Context) error {
+	return s.withMigrationLock(ctx, func(tx *gorm.DB, applied map[uint]SchemaMigration) error {
+		for _, m := range sortedMigrations() {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+
+			record := SchemaMigration{Version: m.Version, Description: m.Description, AppliedAt: time.Now()}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Services) MigrateDown(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, ErrMigrationStepsInvalid
+	}
+
+	var rolledBack int
+	err := s.withMigrationLock(ctx, func(tx *gorm.DB, applied map[uint]SchemaMigration) error {
+		sorted := sortedMigrations()
+		for i := len(sorted) - 1; i >= 0 && rolledBack < steps; i-- {
+			m := sorted[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+
+			if err := tx.Delete(&SchemaMigration{}, m.Version).Error; err != nil {
+				return err
+			}
+			rolledBack++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rolledBack, nil
+}
+
+func (s *Services) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(s.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, m := range sortedMigrations() {
+		status := MigrationStatus{Version: m.Version, Description: m.Description}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (s *Services) withMigrationLock(ctx context.Context, fn func(*gorm.DB, map[uint]SchemaMigration) error) error {
+	ctx, cancel := context.WithTimeout(ctx, migrationTimeout)
+	defer cancel()
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.AutoMigrate(&SchemaMigration{}); err != nil {
+			return err
+		}
+
+		applied, err := appliedMigrations(tx)
+		if err != nil {
+			return err
+		}
+
+		return fn(tx, applied)
+	})
+}
+
+func appliedMigrations(db *gorm.DB) (map[uint]SchemaMigration, error) {
+	applied := make(map[uint]SchemaMigration)
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func sortedMigrations() []Migration {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return sorted
+}
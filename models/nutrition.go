@@ -0,0 +1,135 @@
+package models
+
+import (
+	"context"
+	"github.com/mpanelo/gocookit/ingredient"
+	"github.com/mpanelo/gocookit/nutrition"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NutritionMatch struct {
+	RecipeID   uint   `gorm:"primaryKey"`
+	Ingredient string `gorm:"primaryKey"`
+	FoodID     string `gorm:"not null"`
+}
+
+type NutritionService interface {
+	NutritionDB
+	Label(context.Context, *Recipe) (*nutrition.Label, error)
+}
+
+type nutritionService struct {
+	NutritionDB
+}
+
+func NewNutritionService(db *gorm.DB) NutritionService {
+	return &nutritionService{&nutritionValidator{&nutritionGorm{db}}}
+}
+
+func (ns *nutritionService) Label(ctx context.Context, recipe *Recipe) (*nutrition.Label, error) {
+	matches, err := ns.ByRecipeID(ctx, recipe.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]string, len(matches))
+	for _, match := range matches {
+		overrides[match.Ingredient] = match.FoodID
+	}
+
+	return nutrition.Analyze(ingredient.ParseLines(recipe.Ingredients), recipe.Servings, overrides), nil
+}
+
+type NutritionDB interface {
+	ByRecipeID(context.Context, uint) ([]NutritionMatch, error)
+	Save(context.Context, *NutritionMatch) error
+	Delete(ctx context.Context, recipeID uint, ingredient string) error
+}
+
+type nutritionValidator struct {
+	NutritionDB
+}
+
+func (nv *nutritionValidator) Save(ctx context.Context, match *NutritionMatch) error {
+	err := runNutritionValidatorFuncs(match,
+		nutritionRecipeIDRequired,
+		nutritionNormalizeIngredient,
+		nutritionIngredientRequired,
+		nutritionFoodValid)
+	if err != nil {
+		return err
+	}
+
+	return nv.NutritionDB.Save(ctx, match)
+}
+
+func (nv *nutritionValidator) Delete(ctx context.Context, recipeID uint, name string) error {
+	if recipeID <= 0 {
+		return ErrIDInvalid
+	}
+	return nv.NutritionDB.Delete(ctx, recipeID, nutrition.Key(name))
+}
+
+func nutritionRecipeIDRequired(match *NutritionMatch) error {
+	if match.RecipeID <= 0 {
+		return ErrNutritionRecipeIDRequired
+	}
+	return nil
+}
+
+func nutritionNormalizeIngredient(match *NutritionMatch) error {
+	match.Ingredient = nutrition.Key(match.Ingredient)
+	return nil
+}
+
+func nutritionIngredientRequired(match *NutritionMatch) error {
+	if match.Ingredient == "" {
+		return ErrNutritionIngredientRequired
+	}
+	return nil
+}
+
+func nutritionFoodValid(match *NutritionMatch) error {
+	if match.FoodID == nutrition.NoMatch {
+		return nil
+	}
+	if _, ok := nutrition.ByID(match.FoodID); !ok {
+		return ErrNutritionFoodInvalid
+	}
+	return nil
+}
+
+type nutritionGorm struct {
+	db *gorm.DB
+}
+
+func (ng *nutritionGorm) ByRecipeID(ctx context.Context, recipeID uint) ([]NutritionMatch, error) {
+	var matches []NutritionMatch
+	result := ng.db.WithContext(ctx).Where("recipe_id = ?", recipeID).Find(&matches)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return matches, nil
+}
+
+func (ng *nutritionGorm) Save(ctx context.Context, match *NutritionMatch) error {
+	result := ng.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(match)
+	return result.Error
+}
+
+func (ng *nutritionGorm) Delete(ctx context.Context, recipeID uint, name string) error {
+	result := ng.db.WithContext(ctx).Where("recipe_id = ? AND ingredient = ?", recipeID, name).Delete(&NutritionMatch{})
+	return result.Error
+}
+
+type nutritionValidatorFunc func(*NutritionMatch) error
+
+func runNutritionValidatorFuncs(match *NutritionMatch, funcs ...nutritionValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
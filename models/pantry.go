@@ -0,0 +1,231 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mpanelo/gocookit/ingredient"
+	"gorm.io/gorm"
+)
+
+const expiresSoonWindow = 3 * 24 * time.Hour
+
+type PantryItem struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index"`
+	Name      string `gorm:"not null"`
+	Quantity  float64
+	Unit      string
+	ExpiresAt *time.Time `gorm:"type:date"`
+}
+
+func (p *PantryItem) Expired() bool {
+	return p.ExpiresAt != nil && p.ExpiresAt.Before(StartOfDay(time.Now()))
+}
+
+func (p *PantryItem) ExpiresSoon() bool {
+	return p.ExpiresAt != nil && !p.Expired() && p.ExpiresAt.Before(time.Now().Add(expiresSoonWindow))
+}
+
+func (p *PantryItem) Ingredient() ingredient.Ingredient {
+	return ingredient.Ingredient{
+		Quantity: p.Quantity,
+		Unit:     p.Unit,
+		Name:     p.Name,
+	}
+}
+
+type RecipeMatch struct {
+	Recipe  Recipe
+	Have    []ingredient.Ingredient
+	Missing []ingredient.Ingredient
+}
+
+func (m *RecipeMatch) Score() float64 {
+	total := len(m.Have) + len(m.Missing)
+	if total == 0 {
+		return 0
+	}
+	return float64(len(m.Have)) / float64(total)
+}
+
+func (m *RecipeMatch) Percent() int {
+	return int(m.Score()*100 + 0.5)
+}
+
+func MatchRecipes(recipes []Recipe, pantry []PantryItem) []RecipeMatch {
+	var available []string
+	for _, item := range pantry {
+		if !item.Expired() {
+			available = append(available, item.Name)
+		}
+	}
+
+	matches := make([]RecipeMatch, 0, len(recipes))
+	for _, recipe := range recipes {
+		match := RecipeMatch{Recipe: recipe}
+		for _, ing := range ingredient.Aggregate(ingredient.ParseLines(recipe.Ingredients)) {
+			if inPantry(ing.Name, available) {
+				match.Have = append(match.Have, ing)
+			} else {
+				match.Missing = append(match.Missing, ing)
+			}
+		}
+		if len(match.Have)+len(match.Missing) > 0 {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score() != matches[j].Score() {
+			return matches[i].Score() > matches[j].Score()
+		}
+		return len(matches[i].Missing) < len(matches[j].Missing)
+	})
+
+	return matches
+}
+
+func inPantry(name string, available []string) bool {
+	for _, a := range available {
+		if ingredient.Matches(name, a) {
+			return true
+		}
+	}
+	return false
+}
+
+type PantryService interface {
+	PantryDB
+}
+
+type pantryService struct {
+	PantryDB
+}
+
+func NewPantryService(db *gorm.DB) PantryService {
+	return &pantryService{&pantryValidator{&pantryGorm{db}}}
+}
+
+type PantryDB interface {
+	ByID(context.Context, uint) (*PantryItem, error)
+	ByUserID(context.Context, uint) ([]PantryItem, error)
+	Create(context.Context, *PantryItem) error
+	Update(context.Context, *PantryItem) error
+	Delete(context.Context, uint) error
+}
+
+type pantryValidator struct {
+	PantryDB
+}
+
+func (pv *pantryValidator) Create(ctx context.Context, item *PantryItem) error {
+	err := runPantryValidatorFuncs(item,
+		pantryUserIDRequired,
+		pantryNormalizeName,
+		pantryNameRequired,
+		pantryQuantityNonNegative)
+	if err != nil {
+		return err
+	}
+
+	return pv.PantryDB.Create(ctx, item)
+}
+
+func (pv *pantryValidator) Update(ctx context.Context, item *PantryItem) error {
+	err := runPantryValidatorFuncs(item,
+		pantryUserIDRequired,
+		pantryNormalizeName,
+		pantryNameRequired,
+		pantryQuantityNonNegative)
+	if err != nil {
+		return err
+	}
+
+	return pv.PantryDB.Update(ctx, item)
+}
+
+func (pv *pantryValidator) Delete(ctx context.Context, id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+	return pv.PantryDB.Delete(ctx, id)
+}
+
+func pantryUserIDRequired(item *PantryItem) error {
+	if item.UserID <= 0 {
+		return ErrPantryUserIDRequired
+	}
+	return nil
+}
+
+func pantryNormalizeName(item *PantryItem) error {
+	item.Name = strings.ToLower(strings.TrimSpace(item.Name))
+	return nil
+}
+
+func pantryNameRequired(item *PantryItem) error {
+	if item.Name == "" {
+		return ErrPantryNameRequired
+	}
+	return nil
+}
+
+func pantryQuantityNonNegative(item *PantryItem) error {
+	if item.Quantity < 0 {
+		return ErrPantryQuantityInvalid
+	}
+	return nil
+}
+
+type pantryGorm struct {
+	db *gorm.DB
+}
+
+func (pg *pantryGorm) ByID(ctx context.Context, id uint) (*PantryItem, error) {
+	var item PantryItem
+	tx := pg.db.WithContext(ctx).Where("id = ?", id)
+
+	if err := first(tx, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (pg *pantryGorm) ByUserID(ctx context.Context, userID uint) ([]PantryItem, error) {
+	var items []PantryItem
+	result := pg.db.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (pg *pantryGorm) Create(ctx context.Context, item *PantryItem) error {
+	result := pg.db.WithContext(ctx).Create(item)
+	return result.Error
+}
+
+func (pg *pantryGorm) Update(ctx context.Context, item *PantryItem) error {
+	result := pg.db.WithContext(ctx).Save(item)
+	return result.Error
+}
+
+func (pg *pantryGorm) Delete(ctx context.Context, id uint) error {
+	result := pg.db.WithContext(ctx).Delete(&PantryItem{}, id)
+	return result.Error
+}
+
+type pantryValidatorFunc func(*PantryItem) error
+
+func runPantryValidatorFuncs(item *PantryItem, funcs ...pantryValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
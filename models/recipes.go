@@ -1,17 +1,106 @@
 package models
 
 import (
+	"context"
+	"fmt"
+	"html/template"
+
+	"github.com/mpanelo/gocookit/dietary"
+	"github.com/mpanelo/gocookit/ingredient"
+	"github.com/mpanelo/gocookit/markdown"
 	"gorm.io/gorm"
 )
 
 type Recipe struct {
 	gorm.Model
-	UserID       uint   `gorm:"not null;index"`
-	Title        string `gorm:"not null"`
-	Description  string
-	Ingredients  string
-	Instructions string
-	Images       []Image `gorm:"-"`
+	UserID          uint   `gorm:"not null;index"`
+	WorkspaceID     *uint  `gorm:"index"`
+	Title           string `gorm:"not null"`
+	Description     string
+	Ingredients     string
+	Instructions    string
+	Servings        int
+	Allergens       dietary.Allergen `gorm:"not null;default:0"`
+	Diets           dietary.Diet     `gorm:"not null;default:0"`
+	DietaryAnalyzed bool             `gorm:"not null;default:false"`
+	ForkedFromID    *uint            `gorm:"index"`
+	ForkedFrom      *Recipe          `gorm:"-"`
+	Forks           []Recipe         `gorm:"-"`
+	Images          []Image          `gorm:"-"`
+}
+
+func (r *Recipe) Fork(userID uint) *Recipe {
+	forkedFromID := r.ID
+	return &Recipe{
+		UserID:       userID,
+		Title:        r.Title,
+		Description:  r.Description,
+		Ingredients:  r.Ingredients,
+		Instructions: r.Instructions,
+		Servings:     r.Servings,
+		ForkedFromID: &forkedFromID,
+	}
+}
+
+func (r *Recipe) ScaledIngredients(servings int) []ingredient.Ingredient {
+	ingredients := ingredient.ParseLines(r.Ingredients)
+	if servings <= 0 || r.Servings <= 0 || servings == r.Servings {
+		return ingredients
+	}
+
+	factor := float64(servings) / float64(r.Servings)
+	for i := range ingredients {
+		ingredients[i] = ingredients[i].Scale(factor)
+	}
+	return ingredients
+}
+
+var renderedMarkdown = markdown.NewCache(1024)
+
+func (r *Recipe) DescriptionHTML() template.HTML {
+	return r.renderMarkdown("description", r.Description)
+}
+
+func (r *Recipe) InstructionsHTML() template.HTML {
+	return r.renderMarkdown("instructions", r.Instructions)
+}
+
+func (r *Recipe) renderMarkdown(field, source string) template.HTML {
+	if r.ID == 0 {
+		return markdown.Render(source)
+	}
+	key := fmt.Sprintf("%d:%d:%s", r.ID, r.UpdatedAt.UnixNano(), field)
+	return renderedMarkdown.Render(key, source)
+}
+
+type RecipeFilter struct {
+	FreeOf dietary.Allergen
+	Diets  dietary.Diet
+}
+
+func (f RecipeFilter) Match(recipe *Recipe) bool {
+	return recipe.DietaryAnalyzed && !recipe.Allergens.Has(f.FreeOf) && recipe.Diets.Has(f.Diets)
+}
+
+func (f RecipeFilter) Apply(recipes []Recipe) []Recipe {
+	if f.FreeOf == 0 && f.Diets == 0 {
+		return recipes
+	}
+
+	filtered := make([]Recipe, 0, len(recipes))
+	for i := range recipes {
+		if f.Match(&recipes[i]) {
+			filtered = append(filtered, recipes[i])
+		}
+	}
+	return filtered
+}
+
+func (r *Recipe) Cover() *Image {
+	if len(r.Images) == 0 {
+		return nil
+	}
+	return &r.Images[0]
 }
 
 func (r *Recipe) ImagesSplitN(n int) [][]Image {
@@ -27,6 +116,8 @@ func (r *Recipe) ImagesSplitN(n int) [][]Image {
 
 type RecipeService interface {
 	RecipeDB
+	Duplicates(context.Context, *Recipe) ([]Recipe, error)
+	DuplicateGroups(ctx context.Context, userID uint) ([][]Recipe, error)
 }
 
 type recipeService struct {
@@ -38,36 +129,63 @@ func NewRecipesService(db *gorm.DB) RecipeService {
 }
 
 type RecipeDB interface {
-	ByID(uint) (*Recipe, error)
-	ByUserID(uint) ([]Recipe, error)
-	Create(*Recipe) error
-	Update(*Recipe) error
+	ByID(context.Context, uint) (*Recipe, error)
+	ByUserID(context.Context, uint) ([]Recipe, error)
+	ByForkedFromID(context.Context, uint) ([]Recipe, error)
+	ByWorkspaceID(context.Context, uint) ([]Recipe, error)
+	Create(context.Context, *Recipe) error
+	Update(context.Context, *Recipe) error
+	Delete(context.Context, uint) error
+	Merge(ctx context.Context, canonicalID uint, duplicateIDs []uint) error
+	IDs(context.Context) ([]uint, error)
+	Reassign(ctx context.Context, fromUserID, toUserID uint) (int64, error)
 }
 
 type recipeValidator struct {
 	RecipeDB
 }
 
-func (rv *recipeValidator) Create(recipe *Recipe) error {
+func (rv *recipeValidator) Create(ctx context.Context, recipe *Recipe) error {
 	err := runRecipeValidatorFuncs(recipe,
 		userIDRequired,
-		titleRequired)
+		titleRequired,
+		servingsNonNegative,
+		setDietaryFlags)
 	if err != nil {
 		return err
 	}
 
-	return rv.RecipeDB.Create(recipe)
+	return rv.RecipeDB.Create(ctx, recipe)
 }
 
-func (rv *recipeValidator) Update(recipe *Recipe) error {
+func (rv *recipeValidator) Update(ctx context.Context, recipe *Recipe) error {
 	err := runRecipeValidatorFuncs(recipe,
 		userIDRequired,
-		titleRequired)
+		titleRequired,
+		servingsNonNegative,
+		setDietaryFlags)
 	if err != nil {
 		return err
 	}
 
-	return rv.RecipeDB.Update(recipe)
+	return rv.RecipeDB.Update(ctx, recipe)
+}
+
+func (rv *recipeValidator) Delete(ctx context.Context, id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+	return rv.RecipeDB.Delete(ctx, id)
+}
+
+func (rv *recipeValidator) Reassign(ctx context.Context, fromUserID, toUserID uint) (int64, error) {
+	if fromUserID <= 0 || toUserID <= 0 {
+		return 0, ErrIDInvalid
+	}
+	if fromUserID == toUserID {
+		return 0, ErrRecipeReassignSameUser
+	}
+	return rv.RecipeDB.Reassign(ctx, fromUserID, toUserID)
 }
 
 func userIDRequired(recipe *Recipe) error {
@@ -84,13 +202,25 @@ func titleRequired(recipe *Recipe) error {
 	return nil
 }
 
+func servingsNonNegative(recipe *Recipe) error {
+	if recipe.Servings < 0 {
+		return ErrServingsInvalid
+	}
+	return nil
+}
+
+func setDietaryFlags(recipe *Recipe) error {
+	recipe.Allergens, recipe.Diets, recipe.DietaryAnalyzed = dietary.Analyze(ingredient.ParseLines(recipe.Ingredients))
+	return nil
+}
+
 type recipeGorm struct {
 	db *gorm.DB
 }
 
-func (rg *recipeGorm) ByID(id uint) (*Recipe, error) {
+func (rg *recipeGorm) ByID(ctx context.Context, id uint) (*Recipe, error) {
 	var recipe Recipe
-	tx := rg.db.Where("id = ?", id)
+	tx := rg.db.WithContext(ctx).Where("id = ?", id)
 
 	if err := first(tx, &recipe); err != nil {
 		return nil, err
@@ -99,25 +229,61 @@ func (rg *recipeGorm) ByID(id uint) (*Recipe, error) {
 	return &recipe, nil
 }
 
-func (rg *recipeGorm) ByUserID(userID uint) ([]Recipe, error) {
+func (rg *recipeGorm) ByUserID(ctx context.Context, userID uint) ([]Recipe, error) {
+	var recipes []Recipe
+	result := rg.db.WithContext(ctx).Where("user_id", userID).Order("updated_at DESC").Find(&recipes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return recipes, nil
+}
+
+func (rg *recipeGorm) ByForkedFromID(ctx context.Context, recipeID uint) ([]Recipe, error) {
 	var recipes []Recipe
-	result := rg.db.Where("user_id", userID).Find(&recipes)
+	result := rg.db.WithContext(ctx).Where("forked_from_id = ?", recipeID).Find(&recipes)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return recipes, nil
 }
 
-func (rg *recipeGorm) Create(recipe *Recipe) error {
-	result := rg.db.Create(recipe)
+func (rg *recipeGorm) ByWorkspaceID(ctx context.Context, workspaceID uint) ([]Recipe, error) {
+	var recipes []Recipe
+	result := rg.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Find(&recipes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return recipes, nil
+}
+
+func (rg *recipeGorm) Create(ctx context.Context, recipe *Recipe) error {
+	result := rg.db.WithContext(ctx).Create(recipe)
 	return result.Error
 }
 
-func (rg *recipeGorm) Update(recipe *Recipe) error {
-	result := rg.db.Save(recipe)
+func (rg *recipeGorm) Update(ctx context.Context, recipe *Recipe) error {
+	result := rg.db.WithContext(ctx).Save(recipe)
 	return result.Error
 }
 
+func (rg *recipeGorm) Delete(ctx context.Context, id uint) error {
+	return rg.db.WithContext(ctx).Delete(&Recipe{}, id).Error
+}
+
+func (rg *recipeGorm) IDs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	result := rg.db.WithContext(ctx).Model(&Recipe{}).Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ids, nil
+}
+
+func (rg *recipeGorm) Reassign(ctx context.Context, fromUserID, toUserID uint) (int64, error) {
+	result := rg.db.WithContext(ctx).Model(&Recipe{}).Where("user_id = ?", fromUserID).Update("user_id", toUserID)
+	return result.RowsAffected, result.Error
+}
+
 type recipeValidatorFunc func(*Recipe) error
 
 func runRecipeValidatorFuncs(recipe *Recipe, funcs ...recipeValidatorFunc) error {
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mpanelo/gocookit/dietary"
+)
+
+func TestRecipeCreate(t *testing.T) {
+	tests := []struct {
+		name   string
+		recipe Recipe
+		want   error
+	}{
+		{"valid", Recipe{UserID: 1, Title: "Bread", Ingredients: "2 cups flour\n1 tsp salt", Servings: 4}, nil},
+		{"zero servings", Recipe{UserID: 1, Title: "Bread"}, nil},
+		{"user required", Recipe{Title: "Bread"}, ErrRecipeUserIDRequired},
+		{"title required", Recipe{UserID: 1}, ErrRecipeTitleRequired},
+		{"servings negative", Recipe{UserID: 1, Title: "Bread", Servings: -1}, ErrServingsInvalid},
+	}
+
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				recipe := tt.recipe
+				err := s.Recipe.Create(ctx, &recipe)
+				if err != tt.want {
+					t.Fatalf("Create() error = %v, want %v", err, tt.want)
+				}
+				if err != nil {
+					return
+				}
+
+				found, err := s.Recipe.ByID(ctx, recipe.ID)
+				if err != nil {
+					t.Fatalf("ByID: %v", err)
+				}
+				if found.Title != tt.recipe.Title || found.Allergens != recipe.Allergens || found.Diets != recipe.Diets {
+					t.Errorf("ByID() = %+v, want %+v", found, recipe)
+				}
+			})
+		}
+	})
+}
+
+func TestRecipeUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(*Recipe)
+		want   error
+	}{
+		{"retitle", func(r *Recipe) { r.Title = "Sourdough" }, nil},
+		{"title required", func(r *Recipe) { r.Title = "" }, ErrRecipeTitleRequired},
+		{"user required", func(r *Recipe) { r.UserID = 0 }, ErrRecipeUserIDRequired},
+		{"servings negative", func(r *Recipe) { r.Servings = -2 }, ErrServingsInvalid},
+	}
+
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				recipe := Recipe{UserID: 1, Title: "Bread"}
+				if err := s.Recipe.Create(ctx, &recipe); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				tt.update(&recipe)
+				if err := s.Recipe.Update(ctx, &recipe); err != tt.want {
+					t.Fatalf("Update() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestRecipeDietaryFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		ingredients   string
+		wantAllergens dietary.Allergen
+		wantDiets     dietary.Diet
+		wantAnalyzed  bool
+	}{
+		{"flour has gluten", "2 cups all-purpose flour\n1 tsp kosher salt", dietary.Gluten, dietary.Vegan | dietary.Vegetarian | dietary.HalalFriendly, true},
+		{"milk and eggs", "1 cup milk\n2 large eggs", dietary.Dairy | dietary.Egg, dietary.Vegetarian | dietary.HalalFriendly, true},
+		{"compound ingredient", "2 tbsp pesto\n1 lb spaghetti", dietary.Nuts | dietary.Dairy | dietary.Gluten, dietary.Vegetarian | dietary.HalalFriendly, true},
+		{"unrecognized ingredient", "1 jar mystery sauce\n2 cups flour", dietary.Gluten, 0, false},
+		{"unrecognized word", "1 cup walnut pesto crumble", dietary.Nuts | dietary.Dairy, 0, false},
+		{"no ingredients", "", 0, 0, false},
+	}
+
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				recipe := Recipe{UserID: 1, Title: "Bread", Ingredients: tt.ingredients, Allergens: dietary.Shellfish, DietaryAnalyzed: true}
+				if err := s.Recipe.Create(ctx, &recipe); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+
+				found, err := s.Recipe.ByID(ctx, recipe.ID)
+				if err != nil {
+					t.Fatalf("ByID: %v", err)
+				}
+				if found.Allergens != tt.wantAllergens || found.Diets != tt.wantDiets || found.DietaryAnalyzed != tt.wantAnalyzed {
+					t.Errorf("flags = %v %v %v, want %v %v %v", found.Allergens.List(), found.Diets.List(), found.DietaryAnalyzed,
+						tt.wantAllergens.List(), tt.wantDiets.List(), tt.wantAnalyzed)
+				}
+			})
+		}
+	})
+}
+
+func TestRecipeFilterFailsClosed(t *testing.T) {
+	recipes := []Recipe{
+		{Title: "analyzed", DietaryAnalyzed: true, Diets: dietary.Vegan},
+		{Title: "contains nuts", DietaryAnalyzed: true, Allergens: dietary.Nuts},
+		{Title: "unanalyzed", Diets: dietary.Vegan},
+	}
+
+	tests := []struct {
+		name   string
+		filter RecipeFilter
+		want   []string
+	}{
+		{"no filter", RecipeFilter{}, []string{"analyzed", "contains nuts", "unanalyzed"}},
+		{"nut free", RecipeFilter{FreeOf: dietary.Nuts}, []string{"analyzed"}},
+		{"vegan", RecipeFilter{Diets: dietary.Vegan}, []string{"analyzed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, recipe := range tt.filter.Apply(recipes) {
+				got = append(got, recipe.Title)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrationBackfillsDietaryFlags(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		if s.db == nil {
+			t.Skip("memory services have no migrations")
+		}
+		ctx := context.Background()
+
+		recipe := Recipe{UserID: 1, Title: "Pesto pasta", Ingredients: "2 tbsp pesto\n1 lb spaghetti"}
+		if err := s.Recipe.Create(ctx, &recipe); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if n, err := s.MigrateDown(ctx, 1); err != nil || n != 1 {
+			t.Fatalf("MigrateDown = %d, %v", n, err)
+		}
+		err := s.db.Model(&v4Recipe{}).Where("id = ?", recipe.ID).Updates(map[string]interface{}{"allergens": 0, "diets": 0}).Error
+		if err != nil {
+			t.Fatalf("reset flags: %v", err)
+		}
+		if err := s.MigrateUp(ctx); err != nil {
+			t.Fatalf("MigrateUp: %v", err)
+		}
+
+		found, err := s.Recipe.ByID(ctx, recipe.ID)
+		if err != nil {
+			t.Fatalf("ByID: %v", err)
+		}
+		if found.Allergens != recipe.Allergens || found.Diets != recipe.Diets || !found.DietaryAnalyzed {
+			t.Errorf("backfilled flags = %v %v %v, want %v %v true", found.Allergens.List(), found.Diets.List(), found.DietaryAnalyzed,
+				recipe.Allergens.List(), recipe.Diets.List())
+		}
+	})
+}
+
+func TestRecipeDeleteAndReassign(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		recipe := Recipe{UserID: 1, Title: "Bread"}
+		if err := s.Recipe.Create(ctx, &recipe); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		reassigns := []struct {
+			name     string
+			from, to uint
+			wantN    int64
+			want     error
+		}{
+			{"from required", 0, 2, 0, ErrIDInvalid},
+			{"to required", 1, 0, 0, ErrIDInvalid},
+			{"same user", 1, 1, 0, ErrRecipeReassignSameUser},
+			{"valid", 1, 2, 1, nil},
+			{"nothing left", 1, 2, 0, nil},
+		}
+		for _, tt := range reassigns {
+			t.Run("reassign "+tt.name, func(t *testing.T) {
+				n, err := s.Recipe.Reassign(ctx, tt.from, tt.to)
+				if err != tt.want || n != tt.wantN {
+					t.Fatalf("Reassign(%d, %d) = %d, %v, want %d, %v", tt.from, tt.to, n, err, tt.wantN, tt.want)
+				}
+			})
+		}
+
+		if err := s.Recipe.Delete(ctx, 0); err != ErrIDInvalid {
+			t.Fatalf("Delete(0) error = %v, want %v", err, ErrIDInvalid)
+		}
+		if err := s.Recipe.Delete(ctx, recipe.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := s.Recipe.ByID(ctx, recipe.ID); err != ErrNotFound {
+			t.Fatalf("ByID after Delete error = %v, want %v", err, ErrNotFound)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mpanelo/gocookit/similarity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RelatedRecipesLimit = 4
+
+	similaritiesPerRecipe = 10
+	similarityMinScore    = 0.1
+	coViewWindow          = 24 * time.Hour
+	coViewSaturation      = 5
+
+	ingredientSimilarityWeight = 0.5
+	titleSimilarityWeight      = 0.3
+	coViewSimilarityWeight     = 0.2
+)
+
+type RecipeSimilarity struct {
+	RecipeID  uint    `gorm:"primaryKey"`
+	SimilarID uint    `gorm:"primaryKey"`
+	Score     float64 `gorm:"not null"`
+}
+
+type RecipeCoView struct {
+	RecipeID uint `gorm:"primaryKey"`
+	OtherID  uint `gorm:"primaryKey"`
+	Count    int  `gorm:"not null;default:0"`
+}
+
+type RecommendationService interface {
+	RecommendationDB
+	Rebuild(ctx context.Context) error
+}
+
+type recommendationService struct {
+	RecommendationDB
+}
+
+func NewRecommendationService(db *gorm.DB) RecommendationService {
+	return &recommendationService{&recommendationValidator{&recommendationGorm{db}}}
+}
+
+func (rs *recommendationService) Rebuild(ctx context.Context) error {
+	recipes, err := rs.Candidates(ctx)
+	if err != nil {
+		return err
+	}
+
+	coViews, err := rs.CoViews(ctx)
+	if err != nil {
+		return err
+	}
+
+	docs := make(map[uint]similarity.Document, len(recipes))
+	buckets := make(map[string][]uint)
+	for _, recipe := range recipes {
+		docs[recipe.ID] = similarity.NewDocument(recipe.Title, recipe.Ingredients)
+		buckets[bucketKey("user", recipe.UserID)] = append(buckets[bucketKey("user", recipe.UserID)], recipe.ID)
+		if recipe.WorkspaceID != nil {
+			key := bucketKey("workspace", *recipe.WorkspaceID)
+			buckets[key] = append(buckets[key], recipe.ID)
+		}
+	}
+
+	scores := make(map[uint]map[uint]float64)
+	for _, ids := range buckets {
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				score := relatedScore(docs[a], docs[b], coViews[coViewKey(a, b)])
+				if score < similarityMinScore {
+					continue
+				}
+				addScore(scores, a, b, score)
+				addScore(scores, b, a, score)
+			}
+		}
+	}
+
+	var similarities []RecipeSimilarity
+	for recipeID, similar := range scores {
+		ranked := make([]RecipeSimilarity, 0, len(similar))
+		for similarID, score := range similar {
+			ranked = append(ranked, RecipeSimilarity{RecipeID: recipeID, SimilarID: similarID, Score: score})
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if ranked[i].Score != ranked[j].Score {
+				return ranked[i].Score > ranked[j].Score
+			}
+			return ranked[i].SimilarID < ranked[j].SimilarID
+		})
+		if len(ranked) > similaritiesPerRecipe {
+			ranked = ranked[:similaritiesPerRecipe]
+		}
+		similarities = append(similarities, ranked...)
+	}
+
+	return rs.ReplaceSimilarities(ctx, similarities)
+}
+
+func relatedScore(a, b similarity.Document, coViews int) float64 {
+	coViewScore := float64(coViews) / coViewSaturation
+	if coViewScore > 1 {
+		coViewScore = 1
+	}
+
+	return ingredientSimilarityWeight*similarity.Jaccard(a.Ingredients, b.Ingredients) +
+		titleSimilarityWeight*similarity.Jaccard(a.Title, b.Title) +
+		coViewSimilarityWeight*coViewScore
+}
+
+func addScore(scores map[uint]map[uint]float64, a, b uint, score float64) {
+	if scores[a] == nil {
+		scores[a] = make(map[uint]float64)
+	}
+	scores[a][b] = score
+}
+
+func bucketKey(kind string, id uint) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+func coViewKey(a, b uint) [2]uint {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]uint{a, b}
+}
+
+type RecommendationDB interface {
+	Related(ctx context.Context, recipeID uint) ([]Recipe, error)
+	RecordCoView(ctx context.Context, userID, recipeID uint) error
+	Candidates(ctx context.Context) ([]Recipe, error)
+	CoViews(ctx context.Context) (map[[2]uint]int, error)
+	ReplaceSimilarities(context.Context, []RecipeSimilarity) error
+}
+
+type recommendationValidator struct {
+	RecommendationDB
+}
+
+func (rv *recommendationValidator) RecordCoView(ctx context.Context, userID, recipeID uint) error {
+	if userID <= 0 || recipeID <= 0 {
+		return ErrIDInvalid
+	}
+	return rv.RecommendationDB.RecordCoView(ctx, userID, recipeID)
+}
+
+type recommendationGorm struct {
+	db *gorm.DB
+}
+
+func (rg *recommendationGorm) Related(ctx context.Context, recipeID uint) ([]Recipe, error) {
+	var recipes []Recipe
+	result := rg.db.WithContext(ctx).
+		Joins("JOIN recipe_similarities ON recipe_similarities.similar_id = recipes.id").
+		Where("recipe_similarities.recipe_id = ?", recipeID).
+		Order("recipe_similarities.score DESC").
+		Limit(similaritiesPerRecipe).
+		Find(&recipes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return recipes, nil
+}
+
+func (rg *recommendationGorm) RecordCoView(ctx context.Context, userID, recipeID uint) error {
+	since := time.Now().Add(-coViewWindow)
+
+	var seen int64
+	err := rg.db.WithContext(ctx).Model(&RecentView{}).
+		Where("user_id = ? AND recipe_id = ? AND viewed_at > ?", userID, recipeID, since).
+		Count(&seen).Error
+	if err != nil {
+		return err
+	}
+	if seen > 0 {
+		return nil
+	}
+
+	var others []uint
+	err = rg.db.WithContext(ctx).Model(&RecentView{}).
+		Where("user_id = ? AND recipe_id <> ? AND viewed_at > ?", userID, recipeID, since).
+		Pluck("recipe_id", &others).Error
+	if err != nil {
+		return err
+	}
+	if len(others) == 0 {
+		return nil
+	}
+
+	coViews := make([]RecipeCoView, len(others))
+	for i, other := range others {
+		key := coViewKey(recipeID, other)
+		coViews[i] = RecipeCoView{RecipeID: key[0], OtherID: key[1], Count: 1}
+	}
+
+	return rg.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recipe_id"}, {Name: "other_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("recipe_co_views.count + 1")}),
+	}).Create(&coViews).Error
+}
+
+func (rg *recommendationGorm) Candidates(ctx context.Context) ([]Recipe, error) {
+	var recipes []Recipe
+	result := rg.db.WithContext(ctx).Select("id", "user_id", "workspace_id", "title", "ingredients").Find(&recipes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return recipes, nil
+}
+
+func (rg *recommendationGorm) CoViews(ctx context.Context) (map[[2]uint]int, error) {
+	var rows []RecipeCoView
+	result := rg.db.WithContext(ctx).Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	coViews := make(map[[2]uint]int, len(rows))
+	for _, row := range rows {
+		coViews[coViewKey(row.RecipeID, row.OtherID)] = row.Count
+	}
+	return coViews, nil
+}
+
+func (rg *recommendationGorm) ReplaceSimilarities(ctx context.Context, similarities []RecipeSimilarity) error {
+	return rg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&RecipeSimilarity{}).Error
+		if err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(similarities, 500).Error
+	})
+}
@@ -1,16 +1,27 @@
 package models
 
 import (
+	"context"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 type Services struct {
-	User   UserService
-	Recipe RecipeService
-	Image  ImageService
-	db     *gorm.DB
+	User           UserService
+	Recipe         RecipeService
+	Image          ImageService
+	Collection     CollectionService
+	Workspace      WorkspaceService
+	MealPlan       MealPlanService
+	ShoppingList   ShoppingListService
+	Pantry         PantryService
+	Nutrition      NutritionService
+	CookLog        CookLogService
+	Comment        CommentService
+	Favorite       FavoriteService
+	Recommendation RecommendationService
+	db             *gorm.DB
 }
 
 type ServicesConfig func(*Services) error
@@ -28,6 +39,18 @@ func WithGorm(connInfo string) ServicesConfig {
 	}
 }
 
+func WithDialector(dialector gorm.Dialector) ServicesConfig {
+	return func(s *Services) error {
+		db, err := gorm.Open(dialector, &gorm.Config{})
+		if err != nil {
+			return err
+		}
+
+		s.db = db
+		return nil
+	}
+}
+
 func WithUser(hmacKey, pepper string) ServicesConfig {
 	return func(s *Services) error {
 		s.User = NewUserService(s.db, hmacKey, pepper)
@@ -42,18 +65,79 @@ func WithRecipe() ServicesConfig {
 	}
 }
 
-func WithImage() ServicesConfig {
+func WithCollection() ServicesConfig {
 	return func(s *Services) error {
-		s.Image = NewImageService()
+		s.Collection = NewCollectionService(s.db)
 		return nil
 	}
 }
 
-func WithLogMode(enabled bool) ServicesConfig {
+func WithWorkspace(hmacKey string) ServicesConfig {
 	return func(s *Services) error {
-		if enabled {
-			s.db.Logger = logger.Default.LogMode(logger.Info)
-		}
+		s.Workspace = NewWorkspaceService(s.db, hmacKey)
+		return nil
+	}
+}
+
+func WithMealPlan() ServicesConfig {
+	return func(s *Services) error {
+		s.MealPlan = NewMealPlanService(s.db)
+		return nil
+	}
+}
+
+func WithShoppingList() ServicesConfig {
+	return func(s *Services) error {
+		s.ShoppingList = NewShoppingListService(s.db)
+		return nil
+	}
+}
+
+func WithPantry() ServicesConfig {
+	return func(s *Services) error {
+		s.Pantry = NewPantryService(s.db)
+		return nil
+	}
+}
+
+func WithNutrition() ServicesConfig {
+	return func(s *Services) error {
+		s.Nutrition = NewNutritionService(s.db)
+		return nil
+	}
+}
+
+func WithCookLog() ServicesConfig {
+	return func(s *Services) error {
+		s.CookLog = NewCookLogService(s.db)
+		return nil
+	}
+}
+
+func WithComment() ServicesConfig {
+	return func(s *Services) error {
+		s.Comment = NewCommentService(s.db)
+		return nil
+	}
+}
+
+func WithFavorite() ServicesConfig {
+	return func(s *Services) error {
+		s.Favorite = NewFavoriteService(s.db)
+		return nil
+	}
+}
+
+func WithRecommendation() ServicesConfig {
+	return func(s *Services) error {
+		s.Recommendation = NewRecommendationService(s.db)
+		return nil
+	}
+}
+
+func WithImage() ServicesConfig {
+	return func(s *Services) error {
+		s.Image = NewImageService()
 		return nil
 	}
 }
@@ -69,15 +153,19 @@ func NewServices(cfgs ...ServicesConfig) (*Services, error) {
 	return &s, nil
 }
 
-func (s *Services) DestructiveReset() error {
-	if err := s.db.Migrator().DropTable(&User{}, &Recipe{}); err != nil {
+func (s *Services) DestructiveReset(ctx context.Context) error {
+	if _, err := s.MigrateDown(ctx, len(migrations)); err != nil {
 		return err
 	}
-	return s.AutoMigrate()
+	return s.MigrateUp(ctx)
 }
 
-func (s *Services) AutoMigrate() error {
-	return s.db.AutoMigrate(&User{}, &Recipe{})
+func (s *Services) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
 }
 
 func (s *Services) Close() error {
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testHMACKey = "test-hmac-key"
+	testPepper  = "test-pepper"
+)
+
+func forEachBackend(t *testing.T, fn func(t *testing.T, s *Services)) {
+	t.Run("memory", func(t *testing.T) {
+		fn(t, &Services{
+			User:   NewMemoryUserService(testHMACKey, testPepper),
+			Recipe: NewMemoryRecipeService(),
+			Image:  NewMemoryImageService(),
+		})
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		dir := t.TempDir()
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		s, err := NewServices(
+			WithSQLite(filepath.Join(dir, "gocookit.db")),
+			WithLogger(func(context.Context) *slog.Logger { return logger }, false),
+			WithUser(testHMACKey, testPepper),
+			WithRecipe(),
+		)
+		if err != nil {
+			t.Fatalf("NewServices: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		s.Image = &imageValidator{&imageService{root: filepath.Join(dir, "images")}}
+
+		if err := s.MigrateUp(context.Background()); err != nil {
+			t.Fatalf("MigrateUp: %v", err)
+		}
+		fn(t, s)
+	})
+}
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mpanelo/gocookit/ingredient"
+	"gorm.io/gorm"
+)
+
+type ShoppingList struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index"`
+	Title  string `gorm:"not null"`
+	Items  []ShoppingListItem
+}
+
+type ShoppingListItem struct {
+	gorm.Model
+	ShoppingListID uint   `gorm:"not null;index"`
+	Name           string `gorm:"not null"`
+	Quantity       float64
+	Unit           string
+	Aisle          string
+	Checked        bool
+}
+
+func (i *ShoppingListItem) Ingredient() ingredient.Ingredient {
+	return ingredient.Ingredient{
+		Quantity: i.Quantity,
+		Unit:     i.Unit,
+		Name:     i.Name,
+	}
+}
+
+func (i *ShoppingListItem) String() string {
+	return i.Ingredient().String()
+}
+
+type ShoppingListAisle struct {
+	Name  string
+	Items []*ShoppingListItem
+}
+
+func (l *ShoppingList) Aisles() []ShoppingListAisle {
+	var aisles []ShoppingListAisle
+	for _, name := range ingredient.Aisles {
+		aisle := ShoppingListAisle{Name: name}
+		for i := range l.Items {
+			if l.Items[i].Aisle == name {
+				aisle.Items = append(aisle.Items, &l.Items[i])
+			}
+		}
+		if len(aisle.Items) > 0 {
+			aisles = append(aisles, aisle)
+		}
+	}
+	return aisles
+}
+
+func (l *ShoppingList) AddIngredients(ingredients []ingredient.Ingredient) {
+	index := make(map[string]int, len(l.Items))
+	for i := range l.Items {
+		index[l.Items[i].Ingredient().Key()] = i
+	}
+
+	for _, ing := range ingredient.Aggregate(ingredients) {
+		if i, ok := index[ing.Key()]; ok {
+			l.Items[i].Quantity += ing.Quantity
+			l.Items[i].Checked = false
+			continue
+		}
+
+		index[ing.Key()] = len(l.Items)
+		l.Items = append(l.Items, ShoppingListItem{
+			ShoppingListID: l.ID,
+			Name:           ing.Name,
+			Quantity:       ing.Quantity,
+			Unit:           ing.Unit,
+			Aisle:          ingredient.Aisle(ing.Name),
+		})
+	}
+}
+
+func (l *ShoppingList) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", l.Title)
+	for _, aisle := range l.Aisles() {
+		fmt.Fprintf(&b, "\n%s\n", aisle.Name)
+		for _, item := range aisle.Items {
+			check := " "
+			if item.Checked {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "[%s] %s\n", check, item)
+		}
+	}
+	return b.String()
+}
+
+func (l *ShoppingList) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", l.Title)
+	for _, aisle := range l.Aisles() {
+		fmt.Fprintf(&b, "\n## %s\n\n", aisle.Name)
+		for _, item := range aisle.Items {
+			check := " "
+			if item.Checked {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", check, item)
+		}
+	}
+	return b.String()
+}
+
+type ShoppingListService interface {
+	ShoppingListDB
+}
+
+type shoppingListService struct {
+	ShoppingListDB
+}
+
+func NewShoppingListService(db *gorm.DB) ShoppingListService {
+	return &shoppingListService{&shoppingListValidator{&shoppingListGorm{db}}}
+}
+
+type ShoppingListDB interface {
+	ByID(context.Context, uint) (*ShoppingList, error)
+	ByUserID(context.Context, uint) ([]ShoppingList, error)
+	Create(context.Context, *ShoppingList) error
+	Update(context.Context, *ShoppingList) error
+	Delete(context.Context, uint) error
+	SaveItem(context.Context, *ShoppingListItem) error
+}
+
+type shoppingListValidator struct {
+	ShoppingListDB
+}
+
+func (sv *shoppingListValidator) Create(ctx context.Context, list *ShoppingList) error {
+	err := runShoppingListValidatorFuncs(list,
+		shoppingListUserIDRequired,
+		shoppingListTitleRequired)
+	if err != nil {
+		return err
+	}
+
+	return sv.ShoppingListDB.Create(ctx, list)
+}
+
+func (sv *shoppingListValidator) Update(ctx context.Context, list *ShoppingList) error {
+	err := runShoppingListValidatorFuncs(list,
+		shoppingListUserIDRequired,
+		shoppingListTitleRequired)
+	if err != nil {
+		return err
+	}
+
+	return sv.ShoppingListDB.Update(ctx, list)
+}
+
+func (sv *shoppingListValidator) Delete(ctx context.Context, id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+	return sv.ShoppingListDB.Delete(ctx, id)
+}
+
+func shoppingListUserIDRequired(list *ShoppingList) error {
+	if list.UserID <= 0 {
+		return ErrShoppingListUserIDRequired
+	}
+	return nil
+}
+
+func shoppingListTitleRequired(list *ShoppingList) error {
+	if list.Title == "" {
+		return ErrShoppingListTitleRequired
+	}
+	return nil
+}
+
+type shoppingListGorm struct {
+	db *gorm.DB
+}
+
+func (sg *shoppingListGorm) ByID(ctx context.Context, id uint) (*ShoppingList, error) {
+	var list ShoppingList
+	tx := sg.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", id)
+
+	if err := first(tx, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (sg *shoppingListGorm) ByUserID(ctx context.Context, userID uint) ([]ShoppingList, error) {
+	var lists []ShoppingList
+	result := sg.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&lists)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return lists, nil
+}
+
+func (sg *shoppingListGorm) Create(ctx context.Context, list *ShoppingList) error {
+	result := sg.db.WithContext(ctx).Create(list)
+	return result.Error
+}
+
+func (sg *shoppingListGorm) Update(ctx context.Context, list *ShoppingList) error {
+	result := sg.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(list)
+	return result.Error
+}
+
+func (sg *shoppingListGorm) Delete(ctx context.Context, id uint) error {
+	return sg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("shopping_list_id = ?", id).Delete(&ShoppingListItem{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&ShoppingList{}, id).Error
+	})
+}
+
+func (sg *shoppingListGorm) SaveItem(ctx context.Context, item *ShoppingListItem) error {
+	result := sg.db.WithContext(ctx).Save(item)
+	return result.Error
+}
+
+type shoppingListValidatorFunc func(*ShoppingList) error
+
+func runShoppingListValidatorFuncs(list *ShoppingList, funcs ...shoppingListValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(list); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package models
+
+import "gorm.io/driver/sqlite"
+
+func WithSQLite(path string) ServicesConfig {
+	return WithDialector(sqlite.Open(path))
+}
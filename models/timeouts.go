@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const queryDeadlineKey = "gocookit:query_deadline"
+
+type queryDeadline struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+func WithQueryTimeout(timeout time.Duration) ServicesConfig {
+	return func(s *Services) error {
+		if timeout <= 0 {
+			return nil
+		}
+
+		start := startQueryDeadline(timeout)
+		cb := s.db.Callback()
+		registrations := []func() error{
+			func() error {
+				return cb.Create().Before("gorm:begin_transaction").Register("timeout:before_create", start)
+			},
+			func() error {
+				return cb.Create().After("gorm:commit_or_rollback_transaction").Register("timeout:after_create", stopQueryDeadline)
+			},
+			func() error { return cb.Query().Before("gorm:query").Register("timeout:before_query", start) },
+			func() error {
+				return cb.Query().After("gorm:after_query").Register("timeout:after_query", stopQueryDeadline)
+			},
+			func() error {
+				return cb.Update().Before("gorm:begin_transaction").Register("timeout:before_update", start)
+			},
+			func() error {
+				return cb.Update().After("gorm:commit_or_rollback_transaction").Register("timeout:after_update", stopQueryDeadline)
+			},
+			func() error {
+				return cb.Delete().Before("gorm:begin_transaction").Register("timeout:before_delete", start)
+			},
+			func() error {
+				return cb.Delete().After("gorm:commit_or_rollback_transaction").Register("timeout:after_delete", stopQueryDeadline)
+			},
+			func() error { return cb.Raw().Before("gorm:raw").Register("timeout:before_raw", start) },
+			func() error { return cb.Raw().After("gorm:raw").Register("timeout:after_raw", stopQueryDeadline) },
+		}
+
+		for _, register := range registrations {
+			if err := register(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func startQueryDeadline(timeout time.Duration) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if _, ok := ctx.Deadline(); ok {
+			return
+		}
+
+		parent := db.Statement.Context
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		db.Statement.Context = ctx
+		db.InstanceSet(queryDeadlineKey, queryDeadline{parent: parent, cancel: cancel})
+	}
+}
+
+func stopQueryDeadline(db *gorm.DB) {
+	v, ok := db.InstanceGet(queryDeadlineKey)
+	if !ok {
+		return
+	}
+	deadline, ok := v.(queryDeadline)
+	if !ok {
+		return
+	}
+
+	deadline.cancel()
+	db.Statement.Context = deadline.parent
+	db.InstanceSet(queryDeadlineKey, nil)
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const queryStartedKey = "gocookit:query_started"
+
+type QueryObserver func(operation, table string, elapsed time.Duration)
+
+func WithQueryTimings(observe QueryObserver) ServicesConfig {
+	return func(s *Services) error {
+		cb := s.db.Callback()
+		registrations := []func() error{
+			func() error {
+				return cb.Create().Before("gorm:create").Register("timings:before_create", startQueryTimer)
+			},
+			func() error {
+				return cb.Create().After("gorm:create").Register("timings:after_create", stopQueryTimer("create", observe))
+			},
+			func() error { return cb.Query().Before("gorm:query").Register("timings:before_query", startQueryTimer) },
+			func() error {
+				return cb.Query().After("gorm:query").Register("timings:after_query", stopQueryTimer("query", observe))
+			},
+			func() error {
+				return cb.Update().Before("gorm:update").Register("timings:before_update", startQueryTimer)
+			},
+			func() error {
+				return cb.Update().After("gorm:update").Register("timings:after_update", stopQueryTimer("update", observe))
+			},
+			func() error {
+				return cb.Delete().Before("gorm:delete").Register("timings:before_delete", startQueryTimer)
+			},
+			func() error {
+				return cb.Delete().After("gorm:delete").Register("timings:after_delete", stopQueryTimer("delete", observe))
+			},
+			func() error { return cb.Row().Before("gorm:row").Register("timings:before_row", startQueryTimer) },
+			func() error {
+				return cb.Row().After("gorm:row").Register("timings:after_row", stopQueryTimer("row", observe))
+			},
+			func() error { return cb.Raw().Before("gorm:raw").Register("timings:before_raw", startQueryTimer) },
+			func() error {
+				return cb.Raw().After("gorm:raw").Register("timings:after_raw", stopQueryTimer("raw", observe))
+			},
+		}
+
+		for _, register := range registrations {
+			if err := register(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func startQueryTimer(db *gorm.DB) {
+	db.InstanceSet(queryStartedKey, time.Now())
+}
+
+func stopQueryTimer(operation string, observe QueryObserver) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(queryStartedKey)
+		if !ok {
+			return
+		}
+		started, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		observe(operation, table, time.Since(started))
+	}
+}
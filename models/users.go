@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -18,11 +19,12 @@ type User struct {
 	PasswordHash string `gorm:"not null"`
 	Remember     string `gorm:"-"`
 	RememberHash string `gorm:"not null"`
+	Disabled     bool   `gorm:"not null;default:false"`
 }
 
 type UserService interface {
 	UserDB
-	Authenticate(string, string) (*User, error)
+	Authenticate(context.Context, string, string) (*User, error)
 }
 
 type userService struct {
@@ -31,18 +33,23 @@ type userService struct {
 }
 
 func NewUserService(db *gorm.DB, hmacKey, pepper string) UserService {
+	return newUserService(&userGorm{db}, hmacKey, pepper)
+}
+
+func newUserService(db UserDB, hmacKey, pepper string) UserService {
 	return &userService{
 		UserDB: &userValidator{
-			UserDB:     &userGorm{db},
+			UserDB:     db,
 			hmac:       hash.NewHmac(hmacKey),
 			emailRegex: regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$"),
+			pepper:     pepper,
 		},
 		pepper: pepper,
 	}
 }
 
-func (us *userService) Authenticate(email, password string) (*User, error) {
-	foundUser, err := us.UserDB.ByEmail(email)
+func (us *userService) Authenticate(ctx context.Context, email, password string) (*User, error) {
+	foundUser, err := us.UserDB.ByEmail(ctx, email)
 	if err != nil {
 		if err == ErrNotFound {
 			return nil, ErrUserCredentialsInvalid
@@ -58,15 +65,20 @@ func (us *userService) Authenticate(email, password string) (*User, error) {
 		return nil, err
 	}
 
+	if foundUser.Disabled {
+		return nil, ErrUserDisabled
+	}
+
 	return foundUser, nil
 }
 
 type UserDB interface {
-	ByID(uint) (*User, error)
-	ByEmail(string) (*User, error)
-	ByRemember(string) (*User, error)
-	Create(*User) error
-	Update(*User) error
+	All(context.Context) ([]User, error)
+	ByID(context.Context, uint) (*User, error)
+	ByEmail(context.Context, string) (*User, error)
+	ByRemember(context.Context, string) (*User, error)
+	Create(context.Context, *User) error
+	Update(context.Context, *User) error
 }
 
 type userValidator struct {
@@ -76,7 +88,7 @@ type userValidator struct {
 	pepper     string
 }
 
-func (uv *userValidator) ByEmail(email string) (*User, error) {
+func (uv *userValidator) ByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
 	user.Email = email
 
@@ -85,10 +97,10 @@ func (uv *userValidator) ByEmail(email string) (*User, error) {
 		return nil, err
 	}
 
-	return uv.UserDB.ByEmail(user.Email)
+	return uv.UserDB.ByEmail(ctx, user.Email)
 }
 
-func (uv *userValidator) ByRemember(remember string) (*User, error) {
+func (uv *userValidator) ByRemember(ctx context.Context, remember string) (*User, error) {
 	var user User
 	user.Remember = remember
 
@@ -100,10 +112,10 @@ func (uv *userValidator) ByRemember(remember string) (*User, error) {
 		return nil, err
 	}
 
-	return uv.UserDB.ByRemember(user.RememberHash)
+	return uv.UserDB.ByRemember(ctx, user.RememberHash)
 }
 
-func (uv *userValidator) Create(user *User) error {
+func (uv *userValidator) Create(ctx context.Context, user *User) error {
 	err := runUserValidatorFuncs(user,
 		uv.requirePassword,
 		uv.passwordMinLength,
@@ -116,16 +128,16 @@ func (uv *userValidator) Create(user *User) error {
 		uv.requireEmail,
 		uv.normalizeEmail,
 		uv.validateEmailFormat,
-		uv.emailIsAvail,
+		uv.emailIsAvail(ctx),
 		uv.requireName)
 	if err != nil {
 		return err
 	}
 
-	return uv.UserDB.Create(user)
+	return uv.UserDB.Create(ctx, user)
 }
 
-func (uv *userValidator) Update(user *User) error {
+func (uv *userValidator) Update(ctx context.Context, user *User) error {
 	err := runUserValidatorFuncs(user,
 		uv.passwordMinLength,
 		uv.generatePasswordHash,
@@ -135,12 +147,12 @@ func (uv *userValidator) Update(user *User) error {
 		uv.requireRememberHash,
 		uv.normalizeEmail,
 		uv.validateEmailFormat,
-		uv.emailIsAvail)
+		uv.emailIsAvail(ctx))
 	if err != nil {
 		return err
 	}
 
-	return uv.UserDB.Update(user)
+	return uv.UserDB.Update(ctx, user)
 }
 
 type userValidatorFunc func(*User) error
@@ -246,20 +258,22 @@ func (uv *userValidator) validateEmailFormat(user *User) error {
 	return nil
 }
 
-func (uv *userValidator) emailIsAvail(user *User) error {
-	foundUser, err := uv.UserDB.ByEmail(user.Email)
-	if err != nil {
-		if err == ErrNotFound {
-			return nil
+func (uv *userValidator) emailIsAvail(ctx context.Context) userValidatorFunc {
+	return func(user *User) error {
+		foundUser, err := uv.UserDB.ByEmail(ctx, user.Email)
+		if err != nil {
+			if err == ErrNotFound {
+				return nil
+			}
+			return err
 		}
-		return err
-	}
 
-	if foundUser.ID != user.ID {
-		return ErrUserEmailTaken
-	}
+		if foundUser.ID != user.ID {
+			return ErrUserEmailTaken
+		}
 
-	return nil
+		return nil
+	}
 }
 
 func (uv *userValidator) requireName(user *User) error {
@@ -283,9 +297,18 @@ type userGorm struct {
 	db *gorm.DB
 }
 
-func (ug *userGorm) ByID(id uint) (*User, error) {
+func (ug *userGorm) All(ctx context.Context) ([]User, error) {
+	var users []User
+	result := ug.db.WithContext(ctx).Order("id").Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
+func (ug *userGorm) ByID(ctx context.Context, id uint) (*User, error) {
 	var user User
-	tx := ug.db.Where("id = ?", id)
+	tx := ug.db.WithContext(ctx).Where("id = ?", id)
 
 	err := first(tx, &user)
 	if err != nil {
@@ -295,9 +318,9 @@ func (ug *userGorm) ByID(id uint) (*User, error) {
 	return &user, nil
 }
 
-func (ug *userGorm) ByEmail(email string) (*User, error) {
+func (ug *userGorm) ByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
-	tx := ug.db.Where("email = ?", email)
+	tx := ug.db.WithContext(ctx).Where("email = ?", email)
 
 	err := first(tx, &user)
 	if err != nil {
@@ -307,9 +330,9 @@ func (ug *userGorm) ByEmail(email string) (*User, error) {
 	return &user, nil
 }
 
-func (ug *userGorm) ByRemember(rememberHash string) (*User, error) {
+func (ug *userGorm) ByRemember(ctx context.Context, rememberHash string) (*User, error) {
 	var user User
-	tx := ug.db.Where("remember_hash", rememberHash)
+	tx := ug.db.WithContext(ctx).Where("remember_hash", rememberHash)
 
 	err := first(tx, &user)
 	if err != nil {
@@ -333,12 +356,12 @@ func first(tx *gorm.DB, dst interface{}) error {
 	return nil
 }
 
-func (ug *userGorm) Create(user *User) error {
-	result := ug.db.Create(user)
+func (ug *userGorm) Create(ctx context.Context, user *User) error {
+	result := ug.db.WithContext(ctx).Create(user)
 	return result.Error
 }
 
-func (ug *userGorm) Update(user *User) error {
-	result := ug.db.Save(user)
+func (ug *userGorm) Update(ctx context.Context, user *User) error {
+	result := ug.db.WithContext(ctx).Save(user)
 	return result.Error
 }
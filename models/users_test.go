@@ -0,0 +1,164 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+func TestUserCreate(t *testing.T) {
+	shortToken := base64.URLEncoding.EncodeToString([]byte("too short"))
+
+	tests := []struct {
+		name string
+		user User
+		want error
+	}{
+		{"valid", User{Name: "Ada", Email: "ada@example.com", Password: "password1"}, nil},
+		{"password required", User{Name: "Ada", Email: "ada@example.com"}, ErrUserPasswordRequired},
+		{"password too short", User{Name: "Ada", Email: "ada@example.com", Password: "short"}, ErrUserPasswordTooShort},
+		{"remember too short", User{Name: "Ada", Email: "ada@example.com", Password: "password1", Remember: shortToken}, ErrUserRememberTooShort},
+		{"email required", User{Name: "Ada", Password: "password1"}, ErrUserEmailRequired},
+		{"email invalid", User{Name: "Ada", Email: "not-an-email", Password: "password1"}, ErrUserEmailInvalid},
+		{"email taken", User{Name: "Ada", Email: "taken@example.com", Password: "password1"}, ErrUserEmailTaken},
+		{"email taken after normalizing", User{Name: "Ada", Email: "  Taken@Example.COM ", Password: "password1"}, ErrUserEmailTaken},
+		{"name required", User{Email: "nameless@example.com", Password: "password1"}, ErrUserNameRequired},
+	}
+
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		taken := User{Name: "Taken", Email: "taken@example.com", Password: "password1"}
+		if err := s.User.Create(ctx, &taken); err != nil {
+			t.Fatalf("Create(taken): %v", err)
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				user := tt.user
+				err := s.User.Create(ctx, &user)
+				if err != tt.want {
+					t.Fatalf("Create() error = %v, want %v", err, tt.want)
+				}
+				if err != nil {
+					return
+				}
+				if user.ID == 0 || user.Password != "" || user.PasswordHash == "" || user.RememberHash == "" {
+					t.Errorf("Create() left user %+v without an ID or hashes", user)
+				}
+			})
+		}
+	})
+}
+
+func TestUserLookups(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		user := User{Name: "Ada", Email: "Ada@Example.com", Password: "password1"}
+		if err := s.User.Create(ctx, &user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		tests := []struct {
+			name   string
+			lookup func() (*User, error)
+			want   error
+		}{
+			{"by email", func() (*User, error) { return s.User.ByEmail(ctx, "ada@example.com") }, nil},
+			{"by email normalized", func() (*User, error) { return s.User.ByEmail(ctx, " ADA@example.com ") }, nil},
+			{"by email missing", func() (*User, error) { return s.User.ByEmail(ctx, "bob@example.com") }, ErrNotFound},
+			{"by remember", func() (*User, error) { return s.User.ByRemember(ctx, user.Remember) }, nil},
+			{"by remember too short", func() (*User, error) {
+				return s.User.ByRemember(ctx, base64.URLEncoding.EncodeToString([]byte("short")))
+			}, ErrUserRememberTooShort},
+			{"by id", func() (*User, error) { return s.User.ByID(ctx, user.ID) }, nil},
+			{"by id missing", func() (*User, error) { return s.User.ByID(ctx, user.ID+1) }, ErrNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := tt.lookup()
+				if err != tt.want {
+					t.Fatalf("lookup error = %v, want %v", err, tt.want)
+				}
+				if err == nil && found.ID != user.ID {
+					t.Errorf("lookup found user %d, want %d", found.ID, user.ID)
+				}
+			})
+		}
+	})
+}
+
+func TestUserUpdate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		other := User{Name: "Bob", Email: "bob@example.com", Password: "password1"}
+		if err := s.User.Create(ctx, &other); err != nil {
+			t.Fatalf("Create(other): %v", err)
+		}
+
+		tests := []struct {
+			name   string
+			update func(*User)
+			want   error
+		}{
+			{"rename", func(u *User) { u.Name = "Ada Lovelace" }, nil},
+			{"new password", func(u *User) { u.Password = "password2" }, nil},
+			{"password too short", func(u *User) { u.Password = "short" }, ErrUserPasswordTooShort},
+			{"email invalid", func(u *User) { u.Email = "not-an-email" }, ErrUserEmailInvalid},
+			{"email taken", func(u *User) { u.Email = "BOB@example.com" }, ErrUserEmailTaken},
+		}
+
+		for i, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				user := User{Name: "Ada", Email: fmt.Sprintf("ada%d@example.com", i), Password: "password1"}
+				if err := s.User.Create(ctx, &user); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				tt.update(&user)
+				if err := s.User.Update(ctx, &user); err != tt.want {
+					t.Fatalf("Update() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestUserAuthenticate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s *Services) {
+		ctx := context.Background()
+		user := User{Name: "Ada", Email: "ada@example.com", Password: "password1"}
+		if err := s.User.Create(ctx, &user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		disabled := User{Name: "Bob", Email: "bob@example.com", Password: "password1", Disabled: true}
+		if err := s.User.Create(ctx, &disabled); err != nil {
+			t.Fatalf("Create(disabled): %v", err)
+		}
+
+		tests := []struct {
+			name     string
+			email    string
+			password string
+			want     error
+		}{
+			{"valid", "ada@example.com", "password1", nil},
+			{"email normalized", " ADA@example.com", "password1", nil},
+			{"wrong password", "ada@example.com", "password2", ErrUserCredentialsInvalid},
+			{"unknown email", "carol@example.com", "password1", ErrUserCredentialsInvalid},
+			{"disabled", "bob@example.com", "password1", ErrUserDisabled},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := s.User.Authenticate(ctx, tt.email, tt.password)
+				if err != tt.want {
+					t.Fatalf("Authenticate() error = %v, want %v", err, tt.want)
+				}
+				if err == nil && found.ID != user.ID {
+					t.Errorf("Authenticate() = user %d, want %d", found.ID, user.ID)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,442 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/mpanelo/gocookit/hash"
+	"github.com/mpanelo/gocookit/rand"
+	"gorm.io/gorm"
+)
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+
+	invitationTTL = 7 * 24 * time.Hour
+)
+
+var (
+	Roles           = []Role{RoleOwner, RoleEditor, RoleViewer}
+	InvitationRoles = []Role{RoleEditor, RoleViewer}
+)
+
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) AtLeast(other Role) bool {
+	return r.rank() >= other.rank()
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type Workspace struct {
+	gorm.Model
+	UserID  uint         `gorm:"not null;index"`
+	Name    string       `gorm:"not null"`
+	Members []Membership `gorm:"-"`
+	Recipes []Recipe     `gorm:"-"`
+}
+
+type Membership struct {
+	WorkspaceID uint `gorm:"primaryKey"`
+	UserID      uint `gorm:"primaryKey;index"`
+	Role        Role `gorm:"not null"`
+	User        User
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Invitation struct {
+	gorm.Model
+	WorkspaceID  uint      `gorm:"not null;index"`
+	CreatedByID  uint      `gorm:"not null"`
+	Role         Role      `gorm:"not null"`
+	Token        string    `gorm:"-"`
+	TokenHash    string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	AcceptedAt   *time.Time
+	AcceptedByID *uint
+	Workspace    Workspace `gorm:"-"`
+}
+
+func (i *Invitation) Expired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+func (i *Invitation) Accepted() bool {
+	return i.AcceptedAt != nil
+}
+
+type WorkspaceService interface {
+	WorkspaceDB
+	Invite(ctx context.Context, workspaceID, createdByID uint, role Role) (*Invitation, error)
+	AcceptInvitation(ctx context.Context, token string, user *User) (*Membership, error)
+}
+
+type workspaceService struct {
+	WorkspaceDB
+}
+
+func NewWorkspaceService(db *gorm.DB, hmacKey string) WorkspaceService {
+	return &workspaceService{
+		WorkspaceDB: &workspaceValidator{
+			WorkspaceDB: &workspaceGorm{db},
+			hmac:        hash.NewHmac(hmacKey),
+		},
+	}
+}
+
+func (ws *workspaceService) Invite(ctx context.Context, workspaceID, createdByID uint, role Role) (*Invitation, error) {
+	token, err := rand.InviteToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := Invitation{
+		WorkspaceID: workspaceID,
+		CreatedByID: createdByID,
+		Role:        role,
+		Token:       token,
+		ExpiresAt:   time.Now().Add(invitationTTL),
+	}
+
+	err = ws.WorkspaceDB.CreateInvitation(ctx, &invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (ws *workspaceService) AcceptInvitation(ctx context.Context, token string, user *User) (*Membership, error) {
+	invitation, err := ws.InvitationByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := ws.WorkspaceDB.Membership(ctx, invitation.WorkspaceID, user.ID)
+	switch err {
+	case nil:
+		return membership, nil
+	case ErrNotFound:
+	default:
+		return nil, err
+	}
+
+	membership = &Membership{
+		WorkspaceID: invitation.WorkspaceID,
+		UserID:      user.ID,
+		Role:        invitation.Role,
+	}
+
+	err = ws.WorkspaceDB.ConsumeInvitation(ctx, invitation, membership)
+	if err != nil {
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+func (ws *workspaceService) InvitationByToken(ctx context.Context, token string) (*Invitation, error) {
+	invitation, err := ws.WorkspaceDB.InvitationByToken(ctx, token)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, ErrInvitationInvalid
+		}
+		return nil, err
+	}
+
+	if invitation.Expired() || invitation.Accepted() {
+		return nil, ErrInvitationInvalid
+	}
+
+	workspace, err := ws.WorkspaceDB.ByID(ctx, invitation.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	invitation.Workspace = *workspace
+
+	return invitation, nil
+}
+
+type WorkspaceDB interface {
+	ByID(context.Context, uint) (*Workspace, error)
+	ByUserID(context.Context, uint) ([]Workspace, error)
+	Create(context.Context, *Workspace) error
+	Update(context.Context, *Workspace) error
+	Members(context.Context, uint) ([]Membership, error)
+	Membership(ctx context.Context, workspaceID, userID uint) (*Membership, error)
+	SaveMembership(context.Context, *Membership) error
+	RemoveMembership(ctx context.Context, workspaceID, userID uint) error
+	CreateInvitation(context.Context, *Invitation) error
+	InvitationByToken(context.Context, string) (*Invitation, error)
+	ConsumeInvitation(context.Context, *Invitation, *Membership) error
+}
+
+type workspaceValidator struct {
+	WorkspaceDB
+	hmac *hash.Hmac
+}
+
+func (wv *workspaceValidator) Create(ctx context.Context, workspace *Workspace) error {
+	err := runWorkspaceValidatorFuncs(workspace,
+		workspaceUserIDRequired,
+		workspaceNameRequired)
+	if err != nil {
+		return err
+	}
+
+	return wv.WorkspaceDB.Create(ctx, workspace)
+}
+
+func (wv *workspaceValidator) Update(ctx context.Context, workspace *Workspace) error {
+	err := runWorkspaceValidatorFuncs(workspace,
+		workspaceUserIDRequired,
+		workspaceNameRequired)
+	if err != nil {
+		return err
+	}
+
+	return wv.WorkspaceDB.Update(ctx, workspace)
+}
+
+func (wv *workspaceValidator) SaveMembership(ctx context.Context, membership *Membership) error {
+	if !membership.Role.Valid() {
+		return ErrRoleInvalid
+	}
+
+	if membership.Role != RoleOwner {
+		if err := wv.ownerRemains(ctx, membership.WorkspaceID, membership.UserID); err != nil {
+			return err
+		}
+	}
+
+	return wv.WorkspaceDB.SaveMembership(ctx, membership)
+}
+
+func (wv *workspaceValidator) RemoveMembership(ctx context.Context, workspaceID, userID uint) error {
+	if err := wv.ownerRemains(ctx, workspaceID, userID); err != nil {
+		return err
+	}
+
+	return wv.WorkspaceDB.RemoveMembership(ctx, workspaceID, userID)
+}
+
+func (wv *workspaceValidator) CreateInvitation(ctx context.Context, invitation *Invitation) error {
+	if !invitation.Role.Valid() {
+		return ErrRoleInvalid
+	}
+
+	if invitation.Role == RoleOwner {
+		return ErrInvitationRoleOwner
+	}
+
+	if invitation.Token == "" {
+		return ErrInvitationTokenRequired
+	}
+	invitation.TokenHash = wv.hmac.Hash(invitation.Token)
+
+	return wv.WorkspaceDB.CreateInvitation(ctx, invitation)
+}
+
+func (wv *workspaceValidator) InvitationByToken(ctx context.Context, token string) (*Invitation, error) {
+	if token == "" {
+		return nil, ErrInvitationInvalid
+	}
+
+	return wv.WorkspaceDB.InvitationByToken(ctx, wv.hmac.Hash(token))
+}
+
+func (wv *workspaceValidator) ConsumeInvitation(ctx context.Context, invitation *Invitation, membership *Membership) error {
+	if !membership.Role.Valid() {
+		return ErrRoleInvalid
+	}
+
+	if invitation.Role == RoleOwner {
+		return ErrInvitationRoleOwner
+	}
+
+	return wv.WorkspaceDB.ConsumeInvitation(ctx, invitation, membership)
+}
+
+func (wv *workspaceValidator) ownerRemains(ctx context.Context, workspaceID, userID uint) error {
+	members, err := wv.WorkspaceDB.Members(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.Role == RoleOwner && member.UserID != userID {
+			return nil
+		}
+	}
+
+	return ErrWorkspaceOwnerRequired
+}
+
+func workspaceUserIDRequired(workspace *Workspace) error {
+	if workspace.UserID <= 0 {
+		return ErrWorkspaceUserIDRequired
+	}
+	return nil
+}
+
+func workspaceNameRequired(workspace *Workspace) error {
+	if workspace.Name == "" {
+		return ErrWorkspaceNameRequired
+	}
+	return nil
+}
+
+type workspaceGorm struct {
+	db *gorm.DB
+}
+
+func (wg *workspaceGorm) ByID(ctx context.Context, id uint) (*Workspace, error) {
+	var workspace Workspace
+	tx := wg.db.WithContext(ctx).Where("id = ?", id)
+
+	if err := first(tx, &workspace); err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+func (wg *workspaceGorm) ByUserID(ctx context.Context, userID uint) ([]Workspace, error) {
+	var workspaces []Workspace
+	result := wg.db.WithContext(ctx).
+		Joins("JOIN memberships ON memberships.workspace_id = workspaces.id").
+		Where("memberships.user_id = ?", userID).
+		Order("workspaces.name").
+		Find(&workspaces)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return workspaces, nil
+}
+
+func (wg *workspaceGorm) Create(ctx context.Context, workspace *Workspace) error {
+	return wg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&Membership{
+			WorkspaceID: workspace.ID,
+			UserID:      workspace.UserID,
+			Role:        RoleOwner,
+		}).Error
+	})
+}
+
+func (wg *workspaceGorm) Update(ctx context.Context, workspace *Workspace) error {
+	result := wg.db.WithContext(ctx).Save(workspace)
+	return result.Error
+}
+
+func (wg *workspaceGorm) Members(ctx context.Context, workspaceID uint) ([]Membership, error) {
+	var members []Membership
+	result := wg.db.WithContext(ctx).Preload("User").
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at").
+		Find(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
+
+func (wg *workspaceGorm) Membership(ctx context.Context, workspaceID, userID uint) (*Membership, error) {
+	var membership Membership
+	tx := wg.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID)
+
+	if err := first(tx, &membership); err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+func (wg *workspaceGorm) SaveMembership(ctx context.Context, membership *Membership) error {
+	result := wg.db.WithContext(ctx).Omit("User").Save(membership)
+	return result.Error
+}
+
+func (wg *workspaceGorm) RemoveMembership(ctx context.Context, workspaceID, userID uint) error {
+	result := wg.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Delete(&Membership{})
+	return result.Error
+}
+
+func (wg *workspaceGorm) CreateInvitation(ctx context.Context, invitation *Invitation) error {
+	result := wg.db.WithContext(ctx).Create(invitation)
+	return result.Error
+}
+
+func (wg *workspaceGorm) InvitationByToken(ctx context.Context, tokenHash string) (*Invitation, error) {
+	var invitation Invitation
+	tx := wg.db.WithContext(ctx).Where("token_hash = ?", tokenHash)
+
+	if err := first(tx, &invitation); err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (wg *workspaceGorm) ConsumeInvitation(ctx context.Context, invitation *Invitation, membership *Membership) error {
+	return wg.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&Invitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Updates(map[string]interface{}{"accepted_at": now, "accepted_by_id": membership.UserID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationInvalid
+		}
+
+		if err := tx.Omit("User").Create(membership).Error; err != nil {
+			return err
+		}
+
+		invitation.AcceptedAt = &now
+		invitation.AcceptedByID = &membership.UserID
+		return nil
+	})
+}
+
+type workspaceValidatorFunc func(*Workspace) error
+
+func runWorkspaceValidatorFuncs(workspace *Workspace, funcs ...workspaceValidatorFunc) error {
+	for _, f := range funcs {
+		if err := f(workspace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package nutrition
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mpanelo/gocookit/ingredient"
+)
+
+const NoMatch = "none"
+
+//go:embed foods.csv
+var foodsCSV string
+
+var (
+	foods     = mustLoadFoods(foodsCSV)
+	foodsByID = indexFoods(foods)
+
+	gramsPerUnit = map[string]float64{
+		"g":  1,
+		"kg": 1000,
+		"oz": 28.35,
+		"lb": 453.6,
+	}
+
+	cupsPerUnit = map[string]float64{
+		"cup":   1,
+		"tbsp":  1.0 / 16,
+		"tsp":   1.0 / 48,
+		"ml":    1 / 236.6,
+		"l":     1000 / 236.6,
+		"pinch": 1.0 / 768,
+		"dash":  1.0 / 384,
+	}
+
+	gramsPerContainer = map[string]float64{
+		"can":     400,
+		"package": 450,
+	}
+
+	DailyValues = Nutrients{
+		Calories:      2000,
+		Protein:       50,
+		Fat:           78,
+		SaturatedFat:  20,
+		Carbohydrates: 275,
+		Fiber:         28,
+		Sugar:         50,
+		Sodium:        2300,
+		Calcium:       1300,
+		Iron:          18,
+		Potassium:     4700,
+		VitaminC:      90,
+	}
+)
+
+type Nutrients struct {
+	Calories      float64
+	Protein       float64
+	Fat           float64
+	SaturatedFat  float64
+	Carbohydrates float64
+	Fiber         float64
+	Sugar         float64
+	Sodium        float64
+	Calcium       float64
+	Iron          float64
+	Potassium     float64
+	VitaminC      float64
+}
+
+func (n Nutrients) Add(o Nutrients) Nutrients {
+	return Nutrients{
+		Calories:      n.Calories + o.Calories,
+		Protein:       n.Protein + o.Protein,
+		Fat:           n.Fat + o.Fat,
+		SaturatedFat:  n.SaturatedFat + o.SaturatedFat,
+		Carbohydrates: n.Carbohydrates + o.Carbohydrates,
+		Fiber:         n.Fiber + o.Fiber,
+		Sugar:         n.Sugar + o.Sugar,
+		Sodium:        n.Sodium + o.Sodium,
+		Calcium:       n.Calcium + o.Calcium,
+		Iron:          n.Iron + o.Iron,
+		Potassium:     n.Potassium + o.Potassium,
+		VitaminC:      n.VitaminC + o.VitaminC,
+	}
+}
+
+func (n Nutrients) Scale(factor float64) Nutrients {
+	return Nutrients{
+		Calories:      n.Calories * factor,
+		Protein:       n.Protein * factor,
+		Fat:           n.Fat * factor,
+		SaturatedFat:  n.SaturatedFat * factor,
+		Carbohydrates: n.Carbohydrates * factor,
+		Fiber:         n.Fiber * factor,
+		Sugar:         n.Sugar * factor,
+		Sodium:        n.Sodium * factor,
+		Calcium:       n.Calcium * factor,
+		Iron:          n.Iron * factor,
+		Potassium:     n.Potassium * factor,
+		VitaminC:      n.VitaminC * factor,
+	}
+}
+
+func (n Nutrients) PercentDaily() Nutrients {
+	percent := func(v, dv float64) float64 {
+		return v / dv * 100
+	}
+	return Nutrients{
+		Calories:      percent(n.Calories, DailyValues.Calories),
+		Protein:       percent(n.Protein, DailyValues.Protein),
+		Fat:           percent(n.Fat, DailyValues.Fat),
+		SaturatedFat:  percent(n.SaturatedFat, DailyValues.SaturatedFat),
+		Carbohydrates: percent(n.Carbohydrates, DailyValues.Carbohydrates),
+		Fiber:         percent(n.Fiber, DailyValues.Fiber),
+		Sugar:         percent(n.Sugar, DailyValues.Sugar),
+		Sodium:        percent(n.Sodium, DailyValues.Sodium),
+		Calcium:       percent(n.Calcium, DailyValues.Calcium),
+		Iron:          percent(n.Iron, DailyValues.Iron),
+		Potassium:     percent(n.Potassium, DailyValues.Potassium),
+		VitaminC:      percent(n.VitaminC, DailyValues.VitaminC),
+	}
+}
+
+type Food struct {
+	ID          string
+	Name        string
+	Aliases     []string
+	Per100g     Nutrients
+	GramsPerCup float64
+	GramsEach   float64
+}
+
+func (f *Food) Grams(ing ingredient.Ingredient) float64 {
+	if g, ok := gramsPerUnit[ing.Unit]; ok {
+		return ing.Quantity * g
+	}
+	if cups, ok := cupsPerUnit[ing.Unit]; ok {
+		return ing.Quantity * cups * f.GramsPerCup
+	}
+	if g, ok := gramsPerContainer[ing.Unit]; ok {
+		return ing.Quantity * g
+	}
+	return ing.Quantity * f.GramsEach
+}
+
+func (f *Food) Nutrients(grams float64) Nutrients {
+	return f.Per100g.Scale(grams / 100)
+}
+
+func Foods() []Food {
+	return foods
+}
+
+func ByID(id string) (*Food, bool) {
+	food, ok := foodsByID[id]
+	return food, ok
+}
+
+func Lookup(name string) (*Food, bool) {
+	var best *Food
+	var bestLen int
+
+	for i := range foods {
+		food := &foods[i]
+		for _, term := range append([]string{food.Name}, food.Aliases...) {
+			if len(term) > bestLen && ingredient.Matches(name, term) {
+				best, bestLen = food, len(term)
+			}
+		}
+	}
+
+	return best, best != nil
+}
+
+func Key(name string) string {
+	return ingredient.Singular(strings.ToLower(strings.TrimSpace(name)))
+}
+
+type Line struct {
+	Ingredient ingredient.Ingredient
+	Food       *Food
+	Grams      float64
+	Manual     bool
+}
+
+func (l Line) Key() string {
+	return Key(l.Ingredient.Name)
+}
+
+func (l Line) Counted() bool {
+	return l.Food != nil && l.Grams > 0
+}
+
+type Label struct {
+	Servings   int
+	Lines      []Line
+	Total      Nutrients
+	PerServing Nutrients
+}
+
+func (l *Label) Unmatched() []Line {
+	var lines []Line
+	for _, line := range l.Lines {
+		if !line.Counted() && line.Ingredient.Quantity > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func Analyze(ingredients []ingredient.Ingredient, servings int, overrides map[string]string) *Label {
+	if servings <= 0 {
+		servings = 1
+	}
+	label := &Label{Servings: servings}
+
+	for _, ing := range ingredients {
+		line := Line{Ingredient: ing}
+
+		if id, ok := overrides[Key(ing.Name)]; ok {
+			line.Manual = true
+			line.Food, _ = ByID(id)
+		} else {
+			line.Food, _ = Lookup(ing.Name)
+		}
+
+		if line.Food != nil {
+			line.Grams = line.Food.Grams(ing)
+			label.Total = label.Total.Add(line.Food.Nutrients(line.Grams))
+		}
+
+		label.Lines = append(label.Lines, line)
+	}
+
+	label.PerServing = label.Total.Scale(1 / float64(servings))
+	return label
+}
+
+func mustLoadFoods(data string) []Food {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make([]Food, 0, len(records))
+	for i, record := range records[1:] {
+		food, err := parseFood(record)
+		if err != nil {
+			panic(fmt.Sprintf("nutrition: foods.csv line %d: %v", i+2, err))
+		}
+		loaded = append(loaded, food)
+	}
+
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].Name < loaded[j].Name
+	})
+	return loaded
+}
+
+func parseFood(record []string) (Food, error) {
+	values := make([]float64, len(record)-3)
+	for i, field := range record[3:] {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return Food{}, err
+		}
+		values[i] = v
+	}
+
+	var aliases []string
+	if record[2] != "" {
+		aliases = strings.Split(record[2], ";")
+	}
+
+	return Food{
+		ID:      record[0],
+		Name:    record[1],
+		Aliases: aliases,
+		Per100g: Nutrients{
+			Calories:      values[0],
+			Protein:       values[1],
+			Fat:           values[2],
+			SaturatedFat:  values[3],
+			Carbohydrates: values[4],
+			Fiber:         values[5],
+			Sugar:         values[6],
+			Sodium:        values[7],
+			Calcium:       values[8],
+			Iron:          values[9],
+			Potassium:     values[10],
+			VitaminC:      values[11],
+		},
+		GramsPerCup: values[12],
+		GramsEach:   values[13],
+	}, nil
+}
+
+func indexFoods(foods []Food) map[string]*Food {
+	index := make(map[string]*Food, len(foods))
+	for i := range foods {
+		index[foods[i].ID] = &foods[i]
+	}
+	return index
+}
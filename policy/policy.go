@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mpanelo/gocookit/models"
+)
+
+type Action string
+
+const (
+	ActionView   Action = "view"
+	ActionEdit   Action = "edit"
+	ActionManage Action = "manage"
+)
+
+type Policy struct {
+	ws models.WorkspaceService
+}
+
+func New(ws models.WorkspaceService) *Policy {
+	return &Policy{ws: ws}
+}
+
+func (p *Policy) Can(ctx context.Context, user *models.User, action Action, resource interface{}) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	switch res := resource.(type) {
+	case *models.Recipe:
+		return p.canRecipe(ctx, user, action, res)
+	case *models.Collection:
+		return res.UserID == user.ID, nil
+	case *models.MealPlanEntry:
+		return res.UserID == user.ID, nil
+	case *models.ShoppingList:
+		return res.UserID == user.ID, nil
+	case *models.PantryItem:
+		return res.UserID == user.ID, nil
+	case *models.CookLog:
+		return res.UserID == user.ID, nil
+	case *models.Comment:
+		return res.UserID == user.ID, nil
+	case *models.Workspace:
+		return p.canWorkspace(ctx, user, action, res.ID)
+	default:
+		return false, fmt.Errorf("policy: unsupported resource type %T", resource)
+	}
+}
+
+func (p *Policy) canRecipe(ctx context.Context, user *models.User, action Action, recipe *models.Recipe) (bool, error) {
+	if recipe.UserID == user.ID {
+		return true, nil
+	}
+
+	if recipe.WorkspaceID == nil {
+		return false, nil
+	}
+
+	return p.canWorkspace(ctx, user, action, *recipe.WorkspaceID)
+}
+
+func (p *Policy) canWorkspace(ctx context.Context, user *models.User, action Action, workspaceID uint) (bool, error) {
+	membership, err := p.ws.Membership(ctx, workspaceID, user.ID)
+	if err != nil {
+		if err == models.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return membership.Role.AtLeast(requiredRole(action)), nil
+}
+
+func requiredRole(action Action) models.Role {
+	switch action {
+	case ActionView:
+		return models.RoleViewer
+	case ActionEdit:
+		return models.RoleEditor
+	default:
+		return models.RoleOwner
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"encoding/base64"
 )
 
-const RememberTokenBytesLen = 32
+const (
+	RememberTokenBytesLen = 32
+	InviteTokenBytesLen   = 32
+	RequestIDBytesLen     = 12
+)
 
 func NBytes(base64String string) (int, error) {
 	b, err := base64.URLEncoding.DecodeString(base64String)
@@ -19,8 +23,16 @@ func RememberToken() (string, error) {
 	return generateRandString(RememberTokenBytesLen)
 }
 
+func InviteToken() (string, error) {
+	return generateRandString(InviteTokenBytesLen)
+}
+
+func RequestID() (string, error) {
+	return generateRandString(RequestIDBytesLen)
+}
+
 func generateRandString(nBytes int) (string, error) {
-	b, err := Bytes(RememberTokenBytesLen)
+	b, err := Bytes(nBytes)
 	if err != nil {
 		return "", err
 	}
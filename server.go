@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 2 * time.Minute
+	writeTimeout      = 2 * time.Minute
+	idleTimeout       = 2 * time.Minute
+	maxHeaderBytes    = 1 << 20
+)
+
+func newHTTPServer(port int, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+}
+
+func runServer(ctx context.Context, cfg Config, handler http.Handler) error {
+	servers := []*http.Server{newHTTPServer(cfg.Port, handler)}
+	errs := make(chan error, 2)
+
+	go func() {
+		if cfg.TLSEnabled() {
+			slog.Info("starting gocookit", "port", cfg.Port, "tls", true)
+			errs <- servers[0].ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+			return
+		}
+		slog.Info("starting gocookit", "port", cfg.Port, "tls", false)
+		errs <- servers[0].ListenAndServe()
+	}()
+
+	if cfg.TLSEnabled() && cfg.RedirectPort > 0 {
+		redirect := newHTTPServer(cfg.RedirectPort, httpsRedirect(cfg.Port))
+		servers = append(servers, redirect)
+		go func() {
+			slog.Info("redirecting http to https", "port", cfg.RedirectPort)
+			errs <- redirect.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			shutdown(servers, time.Duration(cfg.ShutdownTimeout)*time.Second)
+			return err
+		}
+	case <-ctx.Done():
+		slog.Info("shutting down, waiting for in-flight requests")
+	}
+
+	return shutdown(servers, time.Duration(cfg.ShutdownTimeout)*time.Second)
+}
+
+func shutdown(servers []*http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var firstErr error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func httpsRedirect(httpsPort int) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(rw, r, target, http.StatusMovedPermanently)
+	}
+}
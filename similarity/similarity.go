@@ -0,0 +1,82 @@
+package similarity
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mpanelo/gocookit/ingredient"
+)
+
+const (
+	DuplicateThreshold = 0.6
+
+	titleWeight      = 0.4
+	ingredientWeight = 0.6
+)
+
+var titleStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "with": true,
+	"my": true, "best": true, "easy": true, "simple": true, "quick": true,
+	"classic": true, "homemade": true, "recipe": true, "copy": true,
+}
+
+type Document struct {
+	Title       map[string]bool
+	Ingredients map[string]bool
+}
+
+func NewDocument(title, ingredients string) Document {
+	return Document{
+		Title:       TitleTerms(title),
+		Ingredients: IngredientTerms(ingredients),
+	}
+}
+
+func TitleTerms(title string) map[string]bool {
+	terms := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if titleStopWords[word] {
+			continue
+		}
+		terms[ingredient.Singular(word)] = true
+	}
+	return terms
+}
+
+func IngredientTerms(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, ing := range ingredient.ParseLines(text) {
+		terms[ingredient.Singular(ing.Name)] = true
+	}
+	return terms
+}
+
+func Jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for term := range a {
+		if b[term] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return float64(intersection) / float64(union)
+}
+
+func Score(a, b Document) float64 {
+	title := Jaccard(a.Title, b.Title)
+	if len(a.Ingredients) == 0 || len(b.Ingredients) == 0 {
+		return title
+	}
+	return titleWeight*title + ingredientWeight*Jaccard(a.Ingredients, b.Ingredients)
+}
+
+func IsDuplicate(a, b Document) bool {
+	return Score(a, b) >= DuplicateThreshold
+}
@@ -1,10 +1,6 @@
 package views
 
-import (
-	"log"
-
-	"github.com/mpanelo/gocookit/models"
-)
+import "github.com/mpanelo/gocookit/models"
 
 const (
 	AlertLevelSuccess = "success"
@@ -18,6 +14,7 @@ type Data struct {
 	Alert *Alert
 	User  *models.User
 	Yield interface{}
+	err   error
 }
 
 func (d *Data) SetSuccess(msg string) {
@@ -27,6 +24,15 @@ func (d *Data) SetSuccess(msg string) {
 	}
 }
 
+func (d *Data) SetWarning(msg, link, linkText string) {
+	d.Alert = &Alert{
+		Level:    AlertLevelWarning,
+		Msg:      msg,
+		Link:     link,
+		LinkText: linkText,
+	}
+}
+
 func (d *Data) SetAlertDanger(err error) {
 	d.Alert = &Alert{
 		Level: AlertLevelDanger,
@@ -35,14 +41,17 @@ func (d *Data) SetAlertDanger(err error) {
 	if alerter, ok := err.(Alerter); ok {
 		d.Alert.Msg = alerter.Alert()
 	} else {
-		log.Println(err)
+		d.err = err
 		d.Alert.Msg = AlertGenericMsg
 	}
 }
 
 type Alert struct {
-	Level string
-	Msg   string
+	Level     string
+	Msg       string
+	Link      string
+	LinkText  string
+	RequestID string
 }
 
 type Alerter interface {
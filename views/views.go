@@ -2,52 +2,139 @@ package views
 
 import (
 	"bytes"
+	"embed"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
-	"log"
+	"io/fs"
 	"net/http"
-	"path/filepath"
+	"os"
 
 	"github.com/gorilla/csrf"
 	"github.com/mpanelo/gocookit/context"
+	"github.com/mpanelo/gocookit/markdown"
+	"github.com/mpanelo/gocookit/models"
 )
 
 const (
-	layoutsDir = "views/layouts"
+	layoutsDir = "layouts"
 	fileExt    = ".html"
-	contentDir = "views/content"
+	contentDir = "content"
 )
 
+//go:embed layouts content
+var embedded embed.FS
+
+var funcs = template.FuncMap{
+	"csrfField": func() (template.HTML, error) {
+		return "", errors.New("csrfField is not yet implemented")
+	},
+	"inc": func(i int) int {
+		return i + 1
+	},
+	"dec": func(i int) int {
+		return i - 1
+	},
+	"markdown": markdown.Render,
+	"seq": func(n int) []int {
+		s := make([]int, n)
+		for i := range s {
+			s[i] = i + 1
+		}
+		return s
+	},
+	"deref": func(i *uint) uint {
+		if i == nil {
+			return 0
+		}
+		return *i
+	},
+}
+
+type Registry struct {
+	source fs.FS
+	reload bool
+	views  []*View
+}
+
+func NewRegistry() *Registry {
+	return &Registry{source: embedded}
+}
+
+func NewReloadingRegistry(dir string) *Registry {
+	return &Registry{source: os.DirFS(dir), reload: true}
+}
+
 type View struct {
+	registry *Registry
+	content  string
+	sample   interface{}
 	template *template.Template
+	err      error
 }
 
-func NewView(content string) *View {
-	files := getLayouts()
-	files = append(files, fmt.Sprintf("%s/%s%s", contentDir, content, fileExt))
+func (vr *Registry) NewView(content string, sample interface{}) *View {
+	v := &View{registry: vr, content: content, sample: sample}
+	v.template, v.err = vr.parse(content)
+	vr.views = append(vr.views, v)
+	return v
+}
 
-	t, err := template.New("").Funcs(template.FuncMap{
-		"csrfField": func() (template.HTML, error) {
-			return "", errors.New("csrfField is not yet implemented")
-		},
-	}).ParseFiles(files...)
-	if err != nil {
-		panic(err)
+func (vr *Registry) parse(content string) (*template.Template, error) {
+	return template.New("").Funcs(funcs).ParseFS(vr.source,
+		fmt.Sprintf("%s/*%s", layoutsDir, fileExt),
+		fmt.Sprintf("%s/%s%s", contentDir, content, fileExt),
+	)
+}
+
+func (v *View) load() (*template.Template, error) {
+	if v.registry.reload {
+		return v.registry.parse(v.content)
 	}
+	return v.template, v.err
+}
 
-	return &View{
-		template: t,
+func (vr *Registry) Check() error {
+	var errs []error
+	for _, v := range vr.views {
+		t, err := v.load()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("views: %s: %w", v.content, err))
+			continue
+		}
+		for _, vd := range sampleData(v.sample) {
+			if err := execute(io.Discard, t, "", vd); err != nil {
+				errs = append(errs, fmt.Errorf("views: %s: %w", v.content, err))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sampleData(yield interface{}) []Data {
+	user := &models.User{Name: "Sample User", Email: "sample@gocookit.io"}
+	return []Data{
+		{},
+		{User: user, Yield: yield},
+		{User: user, Yield: yield, Alert: &Alert{Level: AlertLevelSuccess, Msg: "Saved"}},
+		{User: user, Alert: &Alert{Level: AlertLevelWarning, Msg: "Heads up", Link: "/", LinkText: "Home"}},
+		{User: user, Alert: &Alert{Level: AlertLevelDanger, Msg: AlertGenericMsg, RequestID: "sample-request-id"}},
 	}
 }
 
-func getLayouts() []string {
-	files, err := filepath.Glob(fmt.Sprintf("%s/*%s", layoutsDir, fileExt))
+func execute(w io.Writer, t *template.Template, csrfField template.HTML, vd Data) error {
+	tpl, err := t.Clone()
 	if err != nil {
-		panic(err)
+		return err
 	}
-	return files
+	tpl.Funcs(template.FuncMap{
+		"csrfField": func() template.HTML {
+			return csrfField
+		},
+	})
+	return tpl.ExecuteTemplate(w, "bootstrap", vd)
 }
 
 func (v *View) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
@@ -68,18 +155,18 @@ func (v *View) Render(rw http.ResponseWriter, r *http.Request, data interface{})
 	}
 
 	vd.User = context.User(r.Context())
+	if vd.err != nil {
+		context.Logger(r.Context()).Error("request failed", "error", vd.err)
+		vd.Alert.RequestID = context.RequestID(r.Context())
+	}
 
-	csrfField := csrf.TemplateField(r)
-	tpl := v.template.Funcs(template.FuncMap{
-		"csrfField": func() template.HTML {
-			return csrfField
-		},
-	})
-
-	err := tpl.ExecuteTemplate(&buf, "bootstrap", vd)
+	t, err := v.load()
+	if err == nil {
+		err = execute(&buf, t, csrf.TemplateField(r), vd)
+	}
 	if err != nil {
-		log.Println(err)
-		http.Error(rw, AlertGenericMsg, http.StatusInternalServerError)
+		context.Logger(r.Context()).Error("template execution failed", "error", err)
+		http.Error(rw, AlertGenericMsg+" Request ID: "+context.RequestID(r.Context()), http.StatusInternalServerError)
 		return
 	}
 